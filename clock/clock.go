@@ -0,0 +1,185 @@
+//Package clock provides an abstraction of time for sources and filters.
+//The Manual clock can be advanced deterministically in tests.
+package clock
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+//Clock defines the interface to access time
+type Clock interface {
+	Now() time.Time
+	NewTicker(d time.Duration) Ticker
+	After(d time.Duration) <-chan time.Time
+}
+
+//Ticker delivers ticks at intervals
+type Ticker interface {
+	C() <-chan time.Time
+	Stop()
+}
+
+//Real is the clock of the system
+var Real Clock = realClock{}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) NewTicker(d time.Duration) Ticker {
+	return &realTicker{time.NewTicker(d)}
+}
+
+func (realClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+type realTicker struct {
+	t *time.Ticker
+}
+
+func (r *realTicker) C() <-chan time.Time {
+	return r.t.C
+}
+
+func (r *realTicker) Stop() {
+	r.t.Stop()
+}
+
+//Or returns c or the Real clock if c is nil
+func Or(c Clock) Clock {
+	if c == nil {
+		return Real
+	}
+	return c
+}
+
+type contextKey struct{}
+
+//NewContext returns a context that carries the clock
+func NewContext(ctx context.Context, c Clock) context.Context {
+	return context.WithValue(ctx, contextKey{}, c)
+}
+
+//FromContext returns the clock of the context or the Real clock
+func FromContext(ctx context.Context) Clock {
+	c, _ := ctx.Value(contextKey{}).(Clock)
+	return Or(c)
+}
+
+//Manual is a clock that only advances when told to.
+//Tickers and timers fire when the clock is advanced past their deadline.
+type Manual struct {
+	mu      sync.Mutex
+	cond    *sync.Cond
+	now     time.Time
+	waiters []*waiter
+}
+
+type waiter struct {
+	when   time.Time
+	period time.Duration
+	ch     chan time.Time
+}
+
+//NewManual returns a manual clock set to the given time
+func NewManual(t time.Time) *Manual {
+	m := &Manual{now: t}
+	m.cond = sync.NewCond(&m.mu)
+	return m
+}
+
+//Now returns the current time of the clock
+func (m *Manual) Now() time.Time {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.now
+}
+
+//NewTicker returns a ticker that fires every d when the clock is advanced
+func (m *Manual) NewTicker(d time.Duration) Ticker {
+	if d <= 0 {
+		panic("clock: non-positive interval for NewTicker")
+	}
+	return &manualTicker{m: m, w: m.add(d, d)}
+}
+
+//After returns a channel that receives the time once the clock has advanced by d
+func (m *Manual) After(d time.Duration) <-chan time.Time {
+	return m.add(d, 0).ch
+}
+
+//Advance moves the clock forward and fires the tickers and timers that are due
+func (m *Manual) Advance(d time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.now = m.now.Add(d)
+	pending := m.waiters[:0]
+	for _, w := range m.waiters {
+		if w.when.After(m.now) {
+			pending = append(pending, w)
+			continue
+		}
+		select {
+		case w.ch <- m.now:
+		default:
+		}
+		if w.period > 0 {
+			for !w.when.After(m.now) {
+				w.when = w.when.Add(w.period)
+			}
+			pending = append(pending, w)
+		}
+	}
+	m.waiters = pending
+}
+
+//BlockUntil waits until n tickers or timers are waiting for the clock
+func (m *Manual) BlockUntil(n int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for len(m.waiters) < n {
+		m.cond.Wait()
+	}
+}
+
+func (m *Manual) add(d, period time.Duration) *waiter {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	w := &waiter{when: m.now.Add(d), period: period, ch: make(chan time.Time, 1)}
+	if d <= 0 {
+		w.ch <- m.now
+		return w
+	}
+	m.waiters = append(m.waiters, w)
+	m.cond.Broadcast()
+	return w
+}
+
+func (m *Manual) remove(w *waiter) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i, other := range m.waiters {
+		if other == w {
+			m.waiters = append(m.waiters[:i], m.waiters[i+1:]...)
+			return
+		}
+	}
+}
+
+type manualTicker struct {
+	m *Manual
+	w *waiter
+}
+
+func (t *manualTicker) C() <-chan time.Time {
+	return t.w.ch
+}
+
+func (t *manualTicker) Stop() {
+	t.m.remove(t.w)
+}
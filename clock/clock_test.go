@@ -0,0 +1,85 @@
+package clock_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/konimarti/flow/clock"
+)
+
+var start = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func TestManualNow(t *testing.T) {
+	c := clock.NewManual(start)
+	c.Advance(time.Minute)
+	if !c.Now().Equal(start.Add(time.Minute)) {
+		t.Errorf("Got %v. Expected %v", c.Now(), start.Add(time.Minute))
+	}
+}
+
+func TestManualTicker(t *testing.T) {
+	c := clock.NewManual(start)
+	ticker := c.NewTicker(time.Second)
+
+	c.Advance(500 * time.Millisecond)
+	select {
+	case <-ticker.C():
+		t.Fatal("ticker should not fire before its interval")
+	default:
+	}
+
+	c.Advance(500 * time.Millisecond)
+	select {
+	case tick := <-ticker.C():
+		if !tick.Equal(start.Add(time.Second)) {
+			t.Errorf("Got %v. Expected %v", tick, start.Add(time.Second))
+		}
+	default:
+		t.Fatal("ticker should fire")
+	}
+
+	ticker.Stop()
+	c.Advance(time.Second)
+	select {
+	case <-ticker.C():
+		t.Fatal("stopped ticker should not fire")
+	default:
+	}
+}
+
+func TestManualAfter(t *testing.T) {
+	c := clock.NewManual(start)
+	ch := c.After(time.Second)
+	c.Advance(time.Second)
+	select {
+	case <-ch:
+	default:
+		t.Fatal("timer should fire")
+	}
+}
+
+func TestBlockUntil(t *testing.T) {
+	c := clock.NewManual(start)
+	done := make(chan bool)
+	go func() {
+		c.BlockUntil(1)
+		done <- true
+	}()
+	c.After(time.Second)
+	select {
+	case <-time.After(time.Second):
+		t.Fatal("BlockUntil should return when a timer is waiting")
+	case <-done:
+	}
+}
+
+func TestContext(t *testing.T) {
+	if clock.FromContext(context.Background()) != clock.Real {
+		t.Error("default clock should be the real clock")
+	}
+	c := clock.NewManual(start)
+	if clock.FromContext(clock.NewContext(context.Background(), c)) != c {
+		t.Error("clock should be taken from the context")
+	}
+}
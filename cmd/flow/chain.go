@@ -0,0 +1,239 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/konimarti/flow/filters"
+)
+
+//aliases are the short names of the built-in filters
+var aliases = map[string]string{
+	"movavg":   "MovingAverage",
+	"avg":      "MovingAverage",
+	"std":      "Stddev",
+	"above":    "AboveFloat64",
+	"below":    "BelowFloat64",
+	"rising":   "OnRisingFlank",
+	"tumbling": "TumblingWindow",
+	"sliding":  "SlidingWindow",
+	"session":  "SessionWindow",
+}
+
+//stage is a filter of a chain with its arguments
+type stage struct {
+	name string
+	args []arg
+}
+
+//arg is a positional argument or a named argument (key=value)
+type arg struct {
+	key    string
+	value  string
+	quoted bool
+}
+
+//parseChain builds the filters of a chain like "movavg(10) | sigma(50, 3)".
+//Arguments are positional in the order of the parameters of the filter or
+//named like window=10. Strings with commas or pipes are quoted with ' or ".
+func parseChain(src string, registry *filters.Registry) (filters.Filter, error) {
+	stages, err := splitChain(src)
+	if err != nil {
+		return nil, err
+	}
+	fs := make([]filters.Filter, len(stages))
+	for i, st := range stages {
+		if fs[i], err = newFilter(st, registry); err != nil {
+			return nil, err
+		}
+	}
+	if len(fs) == 1 {
+		return fs[0], nil
+	}
+	return filters.NewChain(fs...), nil
+}
+
+//newFilter creates the filter of a stage
+func newFilter(st stage, registry *filters.Registry) (filters.Filter, error) {
+	name := st.name
+	if alias, ok := aliases[strings.ToLower(name)]; ok {
+		name = alias
+	}
+	spec, ok := registry.Lookup(name)
+	if !ok {
+		return nil, fmt.Errorf("unknown filter %s", st.name)
+	}
+	params := make(map[string]interface{}, len(st.args))
+	named := false
+	for i, a := range st.args {
+		key := a.key
+		if key == "" {
+			if named {
+				return nil, fmt.Errorf("%s: positional argument %s after named arguments", spec.Name, a.value)
+			}
+			if i >= len(spec.Params) {
+				return nil, fmt.Errorf("%s: too many arguments", spec.Name)
+			}
+			key = spec.Params[i].Name
+		} else {
+			named = true
+		}
+		if _, ok := params[key]; ok {
+			return nil, fmt.Errorf("%s: parameter %s is given twice", spec.Name, key)
+		}
+		params[key] = a.parse(spec, key)
+	}
+	return registry.New(spec.Name, params)
+}
+
+//parse returns the value of an argument. Unquoted numbers and booleans
+//are converted for parameters of any type; the registry parses the other strings.
+func (a arg) parse(spec filters.Spec, key string) interface{} {
+	if a.quoted {
+		return a.value
+	}
+	for _, p := range spec.Params {
+		if p.Name != key || p.Type != filters.AnyParam {
+			continue
+		}
+		if f, err := strconv.ParseFloat(a.value, 64); err == nil {
+			return f
+		}
+		if b, err := strconv.ParseBool(a.value); err == nil {
+			return b
+		}
+	}
+	return a.value
+}
+
+//splitChain splits a chain into its stages and arguments
+func splitChain(src string) ([]stage, error) {
+	var stages []stage
+	s := &scanner{src: src}
+	for {
+		s.skipSpace()
+		start := s.pos
+		for s.pos < len(s.src) && isNameChar(s.src[s.pos]) {
+			s.pos++
+		}
+		if start == s.pos {
+			if s.pos == len(s.src) {
+				return nil, fmt.Errorf("missing filter at end of chain")
+			}
+			return nil, fmt.Errorf("expected a filter name at %d, got %q", s.pos, s.src[s.pos])
+		}
+		st := stage{name: s.src[start:s.pos]}
+		s.skipSpace()
+		if s.peek() == '(' {
+			s.pos++
+			args, err := s.args()
+			if err != nil {
+				return nil, fmt.Errorf("%s: %v", st.name, err)
+			}
+			st.args = args
+			s.skipSpace()
+		}
+		stages = append(stages, st)
+		switch s.peek() {
+		case 0:
+			return stages, nil
+		case '|':
+			s.pos++
+		default:
+			return nil, fmt.Errorf("expected | at %d, got %q", s.pos, s.src[s.pos])
+		}
+	}
+}
+
+// scanner reads the stages of a chain
+type scanner struct {
+	src string
+	pos int
+}
+
+//peek returns the next byte or 0 at the end
+func (s *scanner) peek() byte {
+	if s.pos < len(s.src) {
+		return s.src[s.pos]
+	}
+	return 0
+}
+
+func (s *scanner) skipSpace() {
+	for s.pos < len(s.src) && (s.src[s.pos] == ' ' || s.src[s.pos] == '\t' || s.src[s.pos] == '\n') {
+		s.pos++
+	}
+}
+
+//args reads the arguments up to the closing parenthesis
+func (s *scanner) args() ([]arg, error) {
+	var args []arg
+	s.skipSpace()
+	if s.peek() == ')' {
+		s.pos++
+		return nil, nil
+	}
+	for {
+		a, err := s.arg()
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, a)
+		s.skipSpace()
+		switch s.peek() {
+		case ',':
+			s.pos++
+		case ')':
+			s.pos++
+			return args, nil
+		case 0:
+			return nil, fmt.Errorf("missing )")
+		default:
+			return nil, fmt.Errorf("unexpected %q at %d", s.src[s.pos], s.pos)
+		}
+	}
+}
+
+//arg reads a quoted or unquoted argument with an optional key
+func (s *scanner) arg() (arg, error) {
+	var a arg
+	s.skipSpace()
+	start := s.pos
+	for s.pos < len(s.src) && isNameChar(s.src[s.pos]) {
+		s.pos++
+	}
+	end := s.pos
+	s.skipSpace()
+	if end > start && s.peek() == '=' {
+		a.key = s.src[start:end]
+		s.pos++
+		s.skipSpace()
+	} else {
+		s.pos = start
+	}
+
+	if q := s.peek(); q == '\'' || q == '"' {
+		end := strings.IndexByte(s.src[s.pos+1:], q)
+		if end < 0 {
+			return a, fmt.Errorf("unterminated string at %d", s.pos)
+		}
+		a.value, a.quoted = s.src[s.pos+1:s.pos+1+end], true
+		s.pos += end + 2
+		return a, nil
+	}
+	start = s.pos
+	for s.pos < len(s.src) && !strings.ContainsRune(",)", rune(s.src[s.pos])) {
+		s.pos++
+	}
+	a.value = strings.TrimSpace(s.src[start:s.pos])
+	if a.value == "" {
+		return a, fmt.Errorf("missing argument at %d", start)
+	}
+	return a, nil
+}
+
+//isNameChar returns true for the characters of names
+func isNameChar(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
@@ -0,0 +1,284 @@
+//Command flow runs a chain of filters over a stream of values for ad-hoc stream analysis in shell pipelines.
+//
+//The values are read line by line from the standard input, from files or from sockets
+//and the notifications of the flow are written to the standard output as text, JSON lines or CSV
+//(see sinks.Writer):
+//
+//	tail -f load.log | flow --chain 'movavg(10) | sigma(50, 3)'
+//	flow --format json --chain 'above(100)' tcp://:9000 udp://:9001
+//	flow --config pipeline.yaml
+//
+//Inputs are files, - for the standard input (default), tcp://host:port, udp://host:port,
+//unix:///path and unixgram:///path. The values of several inputs are merged.
+//
+//The chain is a list of filters separated by |. The arguments of a filter are positional
+//in the order of its parameters or named like window=10. The filters of the
+//filters.DefaultRegistry are available; flow --list prints them with their parameters.
+//The Print filter writes to the standard output between the notifications.
+//Alternatively, the source and the filters are defined in a configuration file (see package config).
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+
+	"github.com/konimarti/flow"
+	"github.com/konimarti/flow/config"
+	"github.com/konimarti/flow/filters"
+	"github.com/konimarti/flow/observer"
+	"github.com/konimarti/flow/sinks"
+)
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	code := run(ctx, os.Args[1:], os.Stdin, os.Stdout, os.Stderr)
+	stop()
+	os.Exit(code)
+}
+
+//run runs the command and returns the exit code
+func run(ctx context.Context, args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("flow", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	chain := fs.String("chain", "", "filter chain, e.g. 'movavg(10) | sigma(50, 3)'")
+	configFile := fs.String("config", "", "configuration file with the source and the filters")
+	decoderName := fs.String("decoder", "float", "decoder of the input: string, float, json or csv")
+	errorsName := fs.String("errors", "forward", "handling of invalid values: forward, skip or stop")
+	format := fs.String("format", "text", "output format: text, json or csv")
+	list := fs.Bool("list", false, "list the available filters")
+	fs.Usage = func() {
+		fmt.Fprintf(stderr, "usage: flow [flags] [input ...]\n\n")
+		fmt.Fprintf(stderr, "inputs: file, - (stdin), tcp://host:port, udp://host:port, unix:///path, unixgram:///path\n\n")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return 0
+		}
+		return 2
+	}
+
+	// the Print filter and the output share stdout
+	stdout = &syncWriter{w: stdout}
+	registry := newRegistry(stdout)
+	if *list {
+		printFilters(stdout, registry)
+		return 0
+	}
+
+	out := &sinks.Writer{W: stdout}
+	switch *format {
+	case "text":
+	case "json":
+		out.Format = sinks.JSONLines
+	case "csv":
+		out.Format = sinks.CSV
+	default:
+		return usageError(stderr, "unknown format %q", *format)
+	}
+
+	var source flow.Source
+	var nf filters.Filter
+	switch {
+	case *configFile != "":
+		if *chain != "" || fs.NArg() > 0 {
+			return usageError(stderr, "--config cannot be combined with --chain or inputs")
+		}
+		loader := config.Loader{Filters: registry}
+		pipeline, err := loader.LoadFile(*configFile)
+		if err != nil {
+			return fail(stderr, err)
+		}
+		source, nf = pipeline.Source, pipeline.Filter
+	default:
+		decoder, err := newDecoder(*decoderName)
+		if err != nil {
+			return usageError(stderr, "%v", err)
+		}
+		policy, err := newPolicy(*errorsName)
+		if err != nil {
+			return usageError(stderr, "%v", err)
+		}
+		nf = &filters.None{}
+		if strings.TrimSpace(*chain) != "" {
+			if nf, err = parseChain(*chain, registry); err != nil {
+				return fail(stderr, err)
+			}
+		}
+		inputs := fs.Args()
+		if len(inputs) == 0 {
+			inputs = []string{"-"}
+		}
+		sources := make([]flow.Source, len(inputs))
+		for i, input := range inputs {
+			s, closer, err := newSource(input, stdin, decoder, policy)
+			if err != nil {
+				return fail(stderr, err)
+			}
+			if closer != nil {
+				defer closer.Close()
+			}
+			sources[i] = s
+		}
+		source = sources[0]
+		if len(sources) > 1 {
+			source = &flow.Merge{Sources: sources, Policy: policy}
+		}
+	}
+
+	o, results := flow.SubscribeWithContext(ctx, nf, source, observer.Policy{MaxLag: 128, Overflow: observer.Block})
+	// the error channel is closed when the flow ends
+	errorsDone := make(chan struct{})
+	go func() {
+		defer close(errorsDone)
+		for err := range o.Errors() {
+			fmt.Fprintf(stderr, "flow: %v\n", err)
+		}
+	}()
+
+	for {
+		select {
+		case <-results.C():
+			if err := out.Write(ctx, results.Value()); err != nil {
+				o.Close()
+				<-errorsDone
+				return fail(stderr, err)
+			}
+		case <-results.Done():
+			<-errorsDone
+			err := results.Err()
+			if err != nil && !errors.Is(err, context.Canceled) {
+				return fail(stderr, err)
+			}
+			return 0
+		}
+	}
+}
+
+//newSource returns the source of an input and the file that must be closed if the flow
+//ends before the file has been read to the end
+func newSource(input string, stdin io.Reader, decoder flow.Decoder, policy flow.ErrorPolicy) (flow.Source, io.Closer, error) {
+	if input == "-" {
+		return &flow.Reader{R: stdin, Decoder: decoder, Policy: policy}, nil, nil
+	}
+	if i := strings.Index(input, "://"); i > 0 {
+		network, address := input[:i], input[i+3:]
+		switch network {
+		case "tcp", "tcp4", "tcp6", "unix":
+			return &flow.Listener{Network: network, Address: address, Decoder: decoder, Policy: policy}, nil, nil
+		case "udp", "udp4", "udp6", "unixgram":
+			return &flow.PacketListener{Network: network, Address: address, Decoder: decoder, Policy: policy}, nil, nil
+		}
+		return nil, nil, fmt.Errorf("unknown network %q", network)
+	}
+	file, err := os.Open(input)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &flow.Reader{R: closingReader{file}, Decoder: decoder, Policy: policy}, file, nil
+}
+
+//closingReader closes the file when it has been read to the end or fails,
+//so that the files of merged inputs are not held open until the other inputs end
+type closingReader struct {
+	file *os.File
+}
+
+func (c closingReader) Read(p []byte) (int, error) {
+	n, err := c.file.Read(p)
+	if err != nil {
+		c.file.Close()
+	}
+	return n, err
+}
+
+//newDecoder returns a decoder by name
+func newDecoder(name string) (flow.Decoder, error) {
+	switch name {
+	case "string":
+		return flow.StringDecoder, nil
+	case "float":
+		return flow.FloatDecoder, nil
+	case "json":
+		return flow.JSONDecoder, nil
+	case "csv":
+		return &flow.CSVDecoder{}, nil
+	}
+	return nil, fmt.Errorf("unknown decoder %q", name)
+}
+
+//newPolicy returns an error policy by name
+func newPolicy(name string) (flow.ErrorPolicy, error) {
+	switch name {
+	case "forward":
+		return flow.ForwardErrors, nil
+	case "skip":
+		return flow.SkipErrors, nil
+	case "stop":
+		return flow.StopOnError, nil
+	}
+	return flow.ForwardErrors, fmt.Errorf("unknown error policy %q", name)
+}
+
+//newRegistry returns the filters of the filters.DefaultRegistry with a Print filter
+//that writes to stdout
+func newRegistry(stdout io.Writer) *filters.Registry {
+	registry := filters.NewRegistry()
+	for _, spec := range filters.DefaultRegistry.List() {
+		if spec.Name == "Print" {
+			spec.New = func(p filters.Params) (filters.Filter, error) {
+				return &filters.Print{Writer: stdout, Prefix: p.String("prefix")}, nil
+			}
+		}
+		registry.MustRegister(spec)
+	}
+	return registry
+}
+
+//syncWriter serializes the writes of the flow and of the output
+type syncWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (s *syncWriter) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.w.Write(p)
+}
+
+//printFilters prints the usage of the filters of a registry with their aliases
+func printFilters(w io.Writer, registry *filters.Registry) {
+	for _, spec := range registry.List() {
+		fmt.Fprintln(w, spec.Usage())
+		var names []string
+		for alias, name := range aliases {
+			if name == spec.Name {
+				names = append(names, alias)
+			}
+		}
+		if len(names) > 0 {
+			sort.Strings(names)
+			fmt.Fprintf(w, "    alias: %s\n", strings.Join(names, ", "))
+		}
+	}
+}
+
+func usageError(w io.Writer, format string, args ...interface{}) int {
+	fmt.Fprintf(w, "flow: "+format+"\n", args...)
+	return 2
+}
+
+func fail(w io.Writer, err error) int {
+	fmt.Fprintf(w, "flow: %v\n", err)
+	return 1
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/konimarti/flow/filters"
+)
+
+func TestParseChain(t *testing.T) {
+	tests := []struct {
+		chain  string
+		values []float64
+		want   []interface{}
+	}{
+		{"movavg(2)", []float64{1, 3, 5}, []interface{}{1.0, 2.0, 4.0}},
+		{"MovingAverage(window=2) | above(2)", []float64{1, 3, 5}, []interface{}{4.0}},
+		{"expr('x > 1', update = \"max(x, 2) * 10\")", []float64{1, 3}, []interface{}{30.0}},
+		{" onvalue(3) ", []float64{1, 3}, []interface{}{3.0}},
+		{"none | lowpass(a=1)", []float64{1, 3}, []interface{}{1.0, 3.0}},
+	}
+	for _, test := range tests {
+		f, err := parseChain(test.chain, filters.DefaultRegistry)
+		if err != nil {
+			t.Errorf("%s: %v", test.chain, err)
+			continue
+		}
+		var got []interface{}
+		for _, v := range test.values {
+			if f.Check(v) {
+				got = append(got, f.Update(v))
+			}
+		}
+		if len(got) != len(test.want) {
+			t.Errorf("%s: got %v. Expected %v", test.chain, got, test.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != test.want[i] {
+				t.Errorf("%s: got %v. Expected %v", test.chain, got, test.want)
+				break
+			}
+		}
+	}
+}
+
+func TestParseChainErrors(t *testing.T) {
+	for _, chain := range []string{
+		"", "movavg(10", "movavg(10) |", "| movavg(10)", "movavg(10) sigma",
+		"unknown(1)", "movavg(1, 2)", "sigma(window=5, 3)", "movavg(10, window=5)",
+		"movavg(x)", "expr('x > 1)", "movavg(,)",
+	} {
+		if _, err := parseChain(chain, filters.DefaultRegistry); err == nil {
+			t.Errorf("%q: expected error", chain)
+		}
+	}
+}
+
+func TestRun(t *testing.T) {
+	tests := []struct {
+		args  []string
+		input string
+		want  string
+		code  int
+	}{
+		{[]string{"--chain", "movavg(2)"}, "1\n3\n5\n", "1\n2\n4\n", 0},
+		{[]string{"--format", "json", "--chain", "above(2)"}, "1\n3\n", "{\"value\":3}\n", 0},
+		{[]string{"--decoder", "json", "--chain", "expr(update='x.v')"}, "{\"v\": 2}\n", "2\n", 0},
+		{[]string{"--errors", "stop"}, "1\nx\n2\n", "1\n", 1},
+		{[]string{"--errors", "skip"}, "1\nx\n2\n", "1\n2\n", 0},
+		{[]string{"--format", "csv", "--decoder", "csv"}, "1,2\n", "1,2\n", 0},
+		{[]string{"--format", "xml"}, "", "", 2},
+		{[]string{"--chain", "movavg(0)"}, "", "", 1},
+		{[]string{"--chain", "print(v) | sink"}, "1\n2\n", "v 1\nv 2\n", 0},
+	}
+	for _, test := range tests {
+		var stdout, stderr bytes.Buffer
+		code := run(context.Background(), test.args, strings.NewReader(test.input), &stdout, &stderr)
+		if code != test.code || stdout.String() != test.want {
+			t.Errorf("%v: got %d %q (%s). Expected %d %q", test.args, code, stdout.String(), stderr.String(), test.code, test.want)
+		}
+	}
+}
+
+func TestRunForwardErrors(t *testing.T) {
+	for i := 0; i < 50; i++ {
+		var stdout, stderr bytes.Buffer
+		code := run(context.Background(), nil, strings.NewReader("1\nx\n2\n"), &stdout, &stderr)
+		if code != 0 || stdout.String() != "1\n2\n" {
+			t.Fatalf("Got %d %q. Expected 0 \"1\\n2\\n\"", code, stdout.String())
+		}
+		if !strings.Contains(stderr.String(), `parsing "x"`) {
+			t.Fatalf("Got stderr %q. Expected the decoding error", stderr.String())
+		}
+	}
+}
+
+func TestRunFiles(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "input.txt")
+	if err := os.WriteFile(input, []byte("1\n2\n3\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	pipeline := filepath.Join(dir, "pipeline.yaml")
+	config := "source:\n  type: file\n  path: " + input + "\n  decoder: float\nfilters:\n  - type: AboveFloat64\n    value: 1\n"
+	if err := os.WriteFile(pipeline, []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, args := range [][]string{
+		{"--chain", "above(1)", input},
+		{"--config", pipeline},
+	} {
+		var stdout, stderr bytes.Buffer
+		if code := run(context.Background(), args, strings.NewReader(""), &stdout, &stderr); code != 0 {
+			t.Errorf("%v: exit code %d: %s", args, code, stderr.String())
+		}
+		if stdout.String() != "2\n3\n" {
+			t.Errorf("%v: got %q. Expected \"2\\n3\\n\"", args, stdout.String())
+		}
+	}
+
+	// the file is closed at the end
+	file, err := os.Open(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	io.ReadAll(closingReader{file})
+	if _, err := file.Read(make([]byte, 1)); !errors.Is(err, os.ErrClosed) {
+		t.Errorf("Got %v. Expected the file to be closed", err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	if code := run(context.Background(), []string{"--config", pipeline, input}, nil, &stdout, &stderr); code != 2 {
+		t.Errorf("Got exit code %d. Expected 2", code)
+	}
+}
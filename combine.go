@@ -0,0 +1,253 @@
+package flow
+
+import (
+	"context"
+	"sync"
+
+	"github.com/konimarti/flow/filters"
+	"github.com/konimarti/flow/observer"
+)
+
+//Merge implements the Source interface and passes the values of all sources
+//to the filters in the order of their arrival. The flow ends when all sources
+//have ended or with the first source that ends with an error.
+//The errors of the sources are handled according to the Policy.
+type Merge struct {
+	Sources []Source
+	Policy  ErrorPolicy
+}
+
+//Run starts the sources and merges their values
+func (m *Merge) Run(nf filters.Filter) observer.Observer {
+	return m.RunContext(context.Background(), nf)
+}
+
+//RunContext starts the sources and merges their values until the context is done
+func (m *Merge) RunContext(ctx context.Context, nf filters.Filter) observer.Observer {
+	return m.job(nf).run(ctx)
+}
+
+//job returns the flow that starts the sources and merges their values
+func (m *Merge) job(nf filters.Filter) job {
+	return job{nf: nf, policy: m.Policy, produce: func(ctx context.Context, emit emitFunc) error {
+		active := len(m.Sources)
+		return combine(ctx, m.Sources, emit, func(i int, v interface{}) bool {
+			return emit(v, nil)
+		}, func(i int) bool {
+			active--
+			return active > 0
+		})
+	}}
+}
+
+//Zip implements the Source interface and combines the n-th values of all sources
+//into a []interface{} with one value per source in the order of the sources.
+//The flow ends when a source has ended and all of its values have been combined,
+//or with the first source that ends with an error.
+//The errors of the sources are handled according to the Policy.
+type Zip struct {
+	Sources []Source
+	Policy  ErrorPolicy
+}
+
+//Run starts the sources and zips their values
+func (z *Zip) Run(nf filters.Filter) observer.Observer {
+	return z.RunContext(context.Background(), nf)
+}
+
+//RunContext starts the sources and zips their values until the context is done
+func (z *Zip) RunContext(ctx context.Context, nf filters.Filter) observer.Observer {
+	return z.job(nf).run(ctx)
+}
+
+//job returns the flow that starts the sources and zips their values
+func (z *Zip) job(nf filters.Filter) job {
+	return job{nf: nf, policy: z.Policy, produce: func(ctx context.Context, emit emitFunc) error {
+		queues := make([][]interface{}, len(z.Sources))
+		ended := make([]bool, len(z.Sources))
+		exhausted := func() bool {
+			for i := range queues {
+				if ended[i] && len(queues[i]) == 0 {
+					return true
+				}
+			}
+			return false
+		}
+		return combine(ctx, z.Sources, emit, func(i int, v interface{}) bool {
+			queues[i] = append(queues[i], v)
+			for _, q := range queues {
+				if len(q) == 0 {
+					return true
+				}
+			}
+			tuple := make([]interface{}, len(queues))
+			for j := range queues {
+				tuple[j] = queues[j][0]
+				queues[j] = queues[j][1:]
+			}
+			return emit(tuple, nil) && !exhausted()
+		}, func(i int) bool {
+			ended[i] = true
+			return !exhausted()
+		})
+	}}
+}
+
+//CombineLatest implements the Source interface and passes a []interface{} with the
+//latest value of every source (in the order of the sources) to the filters whenever
+//a source sends a value, once all sources have sent a value.
+//The flow ends when all sources have ended or with the first source that ends with an error.
+//The errors of the sources are handled according to the Policy.
+type CombineLatest struct {
+	Sources []Source
+	Policy  ErrorPolicy
+}
+
+//Run starts the sources and combines their latest values
+func (c *CombineLatest) Run(nf filters.Filter) observer.Observer {
+	return c.RunContext(context.Background(), nf)
+}
+
+//RunContext starts the sources and combines their latest values until the context is done
+func (c *CombineLatest) RunContext(ctx context.Context, nf filters.Filter) observer.Observer {
+	return c.job(nf).run(ctx)
+}
+
+//job returns the flow that starts the sources and combines their latest values
+func (c *CombineLatest) job(nf filters.Filter) job {
+	return job{nf: nf, policy: c.Policy, produce: func(ctx context.Context, emit emitFunc) error {
+		latest := make([]interface{}, len(c.Sources))
+		has := make([]bool, len(c.Sources))
+		missing, active := len(c.Sources), len(c.Sources)
+		return combine(ctx, c.Sources, emit, func(i int, v interface{}) bool {
+			if !has[i] {
+				has[i] = true
+				missing--
+			}
+			latest[i] = v
+			if missing > 0 {
+				return true
+			}
+			tuple := make([]interface{}, len(latest))
+			copy(tuple, latest)
+			return emit(tuple, nil)
+		}, func(i int) bool {
+			active--
+			// a source without values prevents any combination
+			return active > 0 && has[i]
+		})
+	}}
+}
+
+//item is a value, an error or the end of the i-th source
+type item struct {
+	index int
+	value interface{}
+	err   error
+	done  bool
+}
+
+//relay is a filter that passes the values of a source to a combinator.
+//It never notifies the subscribers of the source.
+type relay struct {
+	ctx   context.Context
+	index int
+	items chan<- item
+}
+
+//Check passes the value to the combinator
+func (r *relay) Check(v interface{}) bool {
+	select {
+	case r.items <- item{index: r.index, value: v}:
+	case <-r.ctx.Done():
+	}
+	return false
+}
+
+//Update is never called
+func (r *relay) Update(v interface{}) interface{} {
+	return v
+}
+
+//combine starts the sources and calls value for every value and end for every source
+//that has ended without an error, until value or end return false.
+//The errors of the sources are emitted. It returns the error of a source that failed.
+func combine(ctx context.Context, sources []Source, emit emitFunc, value func(int, interface{}) bool, end func(int) bool) error {
+	if len(sources) == 0 {
+		return nil
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	items := make(chan item)
+	send := func(it item) {
+		select {
+		case items <- it:
+		case <-ctx.Done():
+		}
+	}
+
+	var wg sync.WaitGroup
+	observers := make([]observer.Observer, len(sources))
+	for i, source := range sources {
+		o := NewWithContext(ctx, &relay{ctx: ctx, index: i, items: items}, source)
+		observers[i] = o
+		sub := o.Subscribe()
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs := o.Errors()
+			for {
+				select {
+				case err, ok := <-errs:
+					if !ok {
+						errs = nil
+						continue
+					}
+					send(item{index: i, err: err})
+				case <-sub.Done():
+					for errs != nil {
+						err, ok := <-errs
+						if !ok {
+							break
+						}
+						send(item{index: i, err: err})
+					}
+					send(item{index: i, err: sub.Err(), done: true})
+					return
+				case <-ctx.Done():
+					return
+				}
+			}
+		}(i)
+	}
+	defer func() {
+		cancel()
+		for _, o := range observers {
+			o.Close()
+		}
+		wg.Wait()
+	}()
+
+	for {
+		select {
+		case it := <-items:
+			switch {
+			case it.done && it.err != nil:
+				return it.err
+			case it.done:
+				if !end(it.index) {
+					return nil
+				}
+			case it.err != nil:
+				if !emit(nil, it.err) {
+					return nil
+				}
+			default:
+				if !value(it.index, it.value) {
+					return nil
+				}
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
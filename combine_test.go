@@ -0,0 +1,106 @@
+package flow_test
+
+import (
+	"errors"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/konimarti/flow"
+	"github.com/konimarti/flow/filters"
+)
+
+func TestMerge(t *testing.T) {
+	observer := flow.New(&filters.None{}, &flow.Merge{Sources: []flow.Source{
+		&flow.Reader{R: strings.NewReader("1\n2\n3\n"), Decoder: flow.FloatDecoder},
+		&flow.Reader{R: strings.NewReader("4\n5\n"), Decoder: flow.FloatDecoder},
+	}})
+	defer observer.Close()
+
+	subscriber := observer.Subscribe()
+	var sum []float64
+	for _, v := range collect(t, subscriber) {
+		sum = append(sum, v.(float64))
+	}
+	sort.Float64s(sum)
+	if !reflect.DeepEqual(sum, []float64{1, 2, 3, 4, 5}) || subscriber.Err() != nil {
+		t.Errorf("Got %v and %v. Expected all values", sum, subscriber.Err())
+	}
+}
+
+func TestMergeError(t *testing.T) {
+	observer := flow.New(&filters.None{}, &flow.Merge{Sources: []flow.Source{
+		&flow.Reader{R: strings.NewReader("1\n")},
+		&flow.Reader{R: failingReader{}},
+	}})
+	subscriber := observer.Subscribe()
+	collect(t, subscriber)
+	if err := subscriber.Err(); err == nil || err.Error() != "read failed" {
+		t.Errorf("Got %v. Expected read failed", err)
+	}
+}
+
+func TestZip(t *testing.T) {
+	observer := flow.New(&filters.None{}, &flow.Zip{Sources: []flow.Source{
+		&flow.Reader{R: strings.NewReader("1\n2\n3\n"), Decoder: flow.FloatDecoder},
+		&flow.Reader{R: strings.NewReader("a\nb\n")},
+	}})
+	defer observer.Close()
+
+	values := collect(t, observer.Subscribe())
+	expected := []interface{}{[]interface{}{1.0, "a"}, []interface{}{2.0, "b"}}
+	if !reflect.DeepEqual(values, expected) {
+		t.Errorf("Got %v. Expected %v", values, expected)
+	}
+}
+
+type difference struct {
+	filters.Model
+}
+
+func (d *difference) Update(v interface{}) interface{} {
+	tuple := v.([]interface{})
+	return tuple[0].(float64) - tuple[1].(float64)
+}
+
+func TestCombineLatest(t *testing.T) {
+	a, b := make(chan interface{}), make(chan interface{})
+	observer := flow.New(&difference{}, &flow.CombineLatest{Sources: []flow.Source{
+		&flow.Chan{Ch: a},
+		&flow.Chan{Ch: b},
+	}})
+	defer observer.Close()
+	subscriber := observer.Subscribe()
+
+	a <- 10.0
+	b <- 2.0
+	expect(t, subscriber, 8.0)
+	a <- 12.0
+	expect(t, subscriber, 10.0)
+	b <- 5.0
+	expect(t, subscriber, 7.0)
+	a <- 20.0
+	expect(t, subscriber, 15.0)
+
+	close(a)
+	close(b)
+	collect(t, subscriber)
+	if subscriber.Err() != nil {
+		t.Errorf("Got %v. Expected end of stream", subscriber.Err())
+	}
+}
+
+func TestCombineErrors(t *testing.T) {
+	observer := flow.New(&filters.None{}, &flow.Merge{
+		Sources: []flow.Source{&flow.FuncErr{
+			Fn:      func() (interface{}, error) { return nil, errors.New("failed") },
+			Refresh: time.Millisecond,
+		}},
+	})
+	defer observer.Close()
+	if err := <-observer.Errors(); err == nil || err.Error() != "failed" {
+		t.Errorf("Got %v. Expected error of the source", err)
+	}
+}
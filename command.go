@@ -0,0 +1,203 @@
+package flow
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/konimarti/flow/clock"
+	"github.com/konimarti/flow/filters"
+	"github.com/konimarti/flow/observer"
+)
+
+//CommandError is the error of a command.
+//It holds a line of the command's stderr or the error of the command, e.g. its exit status.
+type CommandError struct {
+	Command string
+	Stderr  string
+	Err     error
+}
+
+func (c *CommandError) Error() string {
+	if c.Err != nil {
+		return fmt.Sprintf("flow: command %s: %v", c.Command, c.Err)
+	}
+	return fmt.Sprintf("flow: command %s: %s", c.Command, c.Stderr)
+}
+
+//Command implements the Source interface and runs an external command.
+//Every line of its stdout is decoded by the Decoder (default StringDecoder) and passed
+//to the filters. Every line of its stderr is reported as a CommandError.
+//With a Refresh, the command is run in regular intervals and a failed run is reported
+//as CommandError. Without a Refresh, the command is run once and its output is streamed;
+//the flow ends when the command exits.
+//Errors are handled according to the Policy.
+type Command struct {
+	Name string
+	Args []string
+	//Dir is the working directory of the command
+	Dir string
+	//Env is the environment of the command (default the environment of the process)
+	Env []string
+	//Refresh is the interval in which the command is run
+	Refresh time.Duration
+	//Timeout kills the command after the given time (default Refresh, none when streaming)
+	Timeout time.Duration
+	Decoder Decoder
+	Policy  ErrorPolicy
+}
+
+//Run runs the command and passes the decoded output to the filters
+func (c *Command) Run(nf filters.Filter) observer.Observer {
+	return c.RunContext(context.Background(), nf)
+}
+
+//RunContext runs the command and passes the decoded output to the filters until the context is done.
+//The command is killed when the context is done.
+func (c *Command) RunContext(ctx context.Context, nf filters.Filter) observer.Observer {
+	return c.job(nf).run(ctx)
+}
+
+//job returns the flow that runs the command and passes the decoded output to the filters
+func (c *Command) job(nf filters.Filter) job {
+	decoder := decoderOr(c.Decoder)
+	return job{nf: nf, policy: c.Policy, produce: func(ctx context.Context, emit emitFunc) error {
+		if c.Refresh <= 0 {
+			ok, err := c.execute(ctx, c.Timeout, decoder, emit)
+			if !ok {
+				return nil
+			}
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return err
+		}
+
+		timeout := c.Timeout
+		if timeout <= 0 {
+			timeout = c.Refresh
+		}
+		ticker := clock.FromContext(ctx).NewTicker(c.Refresh)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C():
+				ok, err := c.execute(ctx, timeout, decoder, emit)
+				if !ok {
+					return nil
+				}
+				if err != nil && ctx.Err() == nil && !emit(nil, err) {
+					return nil
+				}
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+	}}
+}
+
+//output is a line of stdout or stderr
+type output struct {
+	line   []byte
+	stderr bool
+}
+
+//execute runs the command once and emits its output.
+//It returns false if the flow has been stopped and the CommandError of a failed run.
+func (c *Command) execute(ctx context.Context, timeout time.Duration, decoder Decoder, emit emitFunc) (bool, error) {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	cmd := exec.CommandContext(ctx, c.Name, c.Args...)
+	cmd.Dir, cmd.Env = c.Dir, c.Env
+
+	// the pipes are closed by us so that a lingering child process
+	// cannot block the flow after the command was killed
+	stdout, stdoutW, err := os.Pipe()
+	if err != nil {
+		return true, err
+	}
+	stderr, stderrW, err := os.Pipe()
+	if err != nil {
+		stdout.Close()
+		stdoutW.Close()
+		return true, err
+	}
+	cmd.Stdout, cmd.Stderr = stdoutW, stderrW
+	err = cmd.Start()
+	stdoutW.Close()
+	stderrW.Close()
+	if err != nil {
+		stdout.Close()
+		stderr.Close()
+		return true, &CommandError{Command: c.String(), Err: err}
+	}
+
+	lines := make(chan output)
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	read := func(f *os.File, isStderr bool) {
+		defer wg.Done()
+		scanner := bufio.NewScanner(f)
+		scanner.Buffer(make([]byte, 4096), maxRecordSize)
+		for scanner.Scan() {
+			line := make([]byte, len(scanner.Bytes()))
+			copy(line, scanner.Bytes())
+			select {
+			case lines <- output{line, isStderr}:
+			case <-done:
+				return
+			}
+		}
+	}
+	wg.Add(2)
+	go read(stdout, false)
+	go read(stderr, true)
+	go func() {
+		wg.Wait()
+		close(lines)
+	}()
+
+	ok := true
+loop:
+	for ok {
+		select {
+		case out, more := <-lines:
+			switch {
+			case !more:
+				break loop
+			case len(out.line) == 0:
+			case out.stderr:
+				ok = emit(nil, &CommandError{Command: c.String(), Stderr: string(out.line)})
+			default:
+				ok = emit(decoder.Decode(out.line))
+			}
+		case <-ctx.Done():
+			break loop
+		}
+	}
+	close(done)
+	stdout.Close()
+	stderr.Close()
+
+	err = cmd.Wait()
+	if ctx.Err() == context.DeadlineExceeded {
+		err = ctx.Err()
+	}
+	if err != nil {
+		return ok, &CommandError{Command: c.String(), Err: err}
+	}
+	return ok, nil
+}
+
+//String returns the command line
+func (c *Command) String() string {
+	return strings.Join(append([]string{c.Name}, c.Args...), " ")
+}
@@ -0,0 +1,75 @@
+package flow_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/konimarti/flow"
+	"github.com/konimarti/flow/clock"
+	"github.com/konimarti/flow/filters"
+)
+
+func TestCommandStream(t *testing.T) {
+	_, subscriber := flow.Subscribe(&filters.None{}, &flow.Command{
+		Name:    "sh",
+		Args:    []string{"-c", "echo 1; echo oops >&2; echo 2"},
+		Decoder: flow.FloatDecoder,
+		Policy:  flow.SkipErrors,
+	})
+	values := collect(t, subscriber)
+	if len(values) != 2 || values[0] != 1.0 || values[1] != 2.0 || subscriber.Err() != nil {
+		t.Errorf("Got %v and %v. Expected [1 2]", values, subscriber.Err())
+	}
+}
+
+func TestCommandStderr(t *testing.T) {
+	observer, subscriber := flow.Subscribe(&filters.None{}, &flow.Command{
+		Name: "sh",
+		Args: []string{"-c", "echo oops >&2; exit 3"},
+	})
+	errs := observer.Errors()
+	collect(t, subscriber)
+
+	err, _ := (<-errs).(*flow.CommandError)
+	if err == nil || err.Stderr != "oops" {
+		t.Errorf("Got %v. Expected stderr as error", err)
+	}
+	err, _ = subscriber.Err().(*flow.CommandError)
+	if err == nil || err.Err == nil {
+		t.Errorf("Got %v. Expected exit status as error", subscriber.Err())
+	}
+}
+
+func TestCommandTimeout(t *testing.T) {
+	_, subscriber := flow.Subscribe(&filters.None{}, &flow.Command{
+		Name:    "sh",
+		Args:    []string{"-c", "echo started; sleep 10"},
+		Timeout: 50 * time.Millisecond,
+	})
+	values := collect(t, subscriber)
+	err, _ := subscriber.Err().(*flow.CommandError)
+	if len(values) != 1 || err == nil || err.Err != context.DeadlineExceeded {
+		t.Errorf("Got %v and %v. Expected command to time out", values, subscriber.Err())
+	}
+}
+
+func TestCommandRefresh(t *testing.T) {
+	c := clock.NewManual(time.Now())
+	ctx := clock.NewContext(context.Background(), c)
+	observer := flow.NewWithContext(ctx, &filters.None{}, &flow.Command{
+		Name:    "echo",
+		Args:    []string{"42"},
+		Refresh: time.Minute,
+		Timeout: 5 * time.Second,
+		Decoder: flow.FloatDecoder,
+	})
+	defer observer.Close()
+	subscriber := observer.Subscribe()
+
+	for i := 0; i < 2; i++ {
+		c.BlockUntil(1)
+		c.Advance(time.Minute)
+		expect(t, subscriber, 42.0)
+	}
+}
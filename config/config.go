@@ -0,0 +1,216 @@
+//Package config builds flows from declarative pipeline definitions in YAML or JSON.
+//
+//A pipeline defines a source and a chain of filters:
+//
+//	source:
+//	  type: tail
+//	  path: /var/log/app.log
+//	  decoder: float
+//	filters:
+//	  - type: MovingAverage
+//	    window: 10
+//	  - switch:
+//	      - type: AboveFloat64
+//	        value: 100
+//	      - type: BelowFloat64
+//	        value: -100
+//
+//The filters are created by name from a filters.Registry and combined with filters.NewChain.
+//A node with "chain" or "switch" instead of "type" builds a nested filters.NewChain
+//or filters.NewSwitch.
+package config
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/konimarti/flow"
+	"github.com/konimarti/flow/filters"
+	"github.com/konimarti/flow/observer"
+)
+
+//Pipeline is a flow that was built from a definition.
+//The filters keep their state between runs.
+type Pipeline struct {
+	Source flow.Source
+	Filter filters.Filter
+}
+
+//Run starts the flow
+func (p *Pipeline) Run() observer.Observer {
+	return flow.New(p.Filter, p.Source)
+}
+
+//RunContext starts the flow until the context is done
+func (p *Pipeline) RunContext(ctx context.Context) observer.Observer {
+	return flow.NewWithContext(ctx, p.Filter, p.Source)
+}
+
+//Subscribe starts the flow with a subscriber that receives all values (see flow.Subscribe)
+func (p *Pipeline) Subscribe() (observer.Observer, observer.Subscriber) {
+	return flow.Subscribe(p.Filter, p.Source)
+}
+
+//SubscribeWithContext starts the flow until the context is done with a subscriber
+//that receives all values (see flow.SubscribeWithContext)
+func (p *Pipeline) SubscribeWithContext(ctx context.Context, policy observer.Policy) (observer.Observer, observer.Subscriber) {
+	return flow.SubscribeWithContext(ctx, p.Filter, p.Source, policy)
+}
+
+//Error is an invalid value at a path of the definition, e.g. filters[1].window
+type Error struct {
+	Path string
+	Err  error
+}
+
+func (e *Error) Error() string {
+	if e.Path == "" {
+		return fmt.Sprintf("config: %v", e.Err)
+	}
+	return fmt.Sprintf("config: %s: %v", e.Path, e.Err)
+}
+
+//Loader builds pipelines with the filters of a registry
+type Loader struct {
+	//Filters is the registry of the named filters (default filters.DefaultRegistry)
+	Filters *filters.Registry
+}
+
+//Load builds a pipeline from a YAML or JSON document with the filters of the filters.DefaultRegistry
+func Load(data []byte) (*Pipeline, error) {
+	return (&Loader{}).Load(data)
+}
+
+//LoadFile builds a pipeline from a YAML or JSON file with the filters of the filters.DefaultRegistry
+func LoadFile(path string) (*Pipeline, error) {
+	return (&Loader{}).LoadFile(path)
+}
+
+//Load builds a pipeline from a YAML or JSON document
+func (l *Loader) Load(data []byte) (*Pipeline, error) {
+	var doc interface{}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, &Error{Err: err}
+	}
+	p, err := newParams("", doc)
+	if err != nil {
+		return nil, err
+	}
+
+	pipeline := &Pipeline{}
+	if v, ok := p.value("source"); ok {
+		pipeline.Source, err = buildSource("source", v)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		p.fail("source", "missing source")
+	}
+
+	pipeline.Filter = &filters.None{}
+	if v, ok := p.value("filters"); ok {
+		pipeline.Filter, err = l.buildList("filters", v, filters.NewChain)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err := p.check(); err != nil {
+		return nil, err
+	}
+	return pipeline, nil
+}
+
+//LoadFile builds a pipeline from a YAML or JSON file
+func (l *Loader) LoadFile(path string) (*Pipeline, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return l.Load(data)
+}
+
+func (l *Loader) registry() *filters.Registry {
+	if l.Filters == nil {
+		return filters.DefaultRegistry
+	}
+	return l.Filters
+}
+
+//buildList builds the filters of a list and combines them
+func (l *Loader) buildList(path string, v interface{}, combine func(...filters.Filter) filters.Filter) (filters.Filter, error) {
+	list, ok := v.([]interface{})
+	if !ok {
+		return nil, &Error{Path: path, Err: fmt.Errorf("expected a list of filters")}
+	}
+	fs := make([]filters.Filter, len(list))
+	for i, node := range list {
+		f, err := l.buildFilter(fmt.Sprintf("%s[%d]", path, i), node)
+		if err != nil {
+			return nil, err
+		}
+		fs[i] = f
+	}
+	return combine(fs...), nil
+}
+
+//buildFilter builds a named filter or a nested chain or switch
+func (l *Loader) buildFilter(path string, node interface{}) (filters.Filter, error) {
+	p, err := newParams(path, node)
+	if err != nil {
+		return nil, err
+	}
+	if v, ok := p.value("chain"); ok {
+		f, err := l.buildList(p.path("chain"), v, filters.NewChain)
+		if err != nil {
+			return nil, err
+		}
+		return f, p.check()
+	}
+	if v, ok := p.value("switch"); ok {
+		f, err := l.buildList(p.path("switch"), v, filters.NewSwitch)
+		if err != nil {
+			return nil, err
+		}
+		return f, p.check()
+	}
+
+	if _, ok := p.m["type"]; !ok {
+		return nil, &Error{Path: path, Err: fmt.Errorf("missing type, chain or switch")}
+	}
+	name := p.String("type", "")
+	if p.err != nil {
+		return nil, p.err
+	}
+	if _, ok := l.registry().Lookup(name); !ok {
+		return nil, &Error{Path: p.path("type"), Err: fmt.Errorf("unknown filter %q", name)}
+	}
+	args := make(map[string]interface{}, len(p.m))
+	for key, v := range p.m {
+		if key != "type" {
+			args[key] = v
+		}
+	}
+	f, err := l.registry().New(name, args)
+	if perr, ok := err.(*filters.ParamError); ok {
+		return nil, &Error{Path: p.path(perr.Param), Err: perr.Err}
+	}
+	if err != nil {
+		return nil, &Error{Path: path, Err: err}
+	}
+	return f, nil
+}
+
+//joinPath appends a key to a path
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	if strings.HasPrefix(key, "[") {
+		return path + key
+	}
+	return path + "." + key
+}
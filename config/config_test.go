@@ -0,0 +1,100 @@
+package config_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/konimarti/flow/config"
+)
+
+func TestLoadYAML(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "values.txt")
+	if err := os.WriteFile(path, []byte("1\n2\n150\n3\n-200\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	pipeline, err := config.Load([]byte(`
+source:
+  type: file
+  path: ` + path + `
+  decoder: float
+filters:
+  - switch:
+      - type: AboveFloat64
+        value: 100
+      - type: BelowFloat64
+        value: -100
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	observer, subscriber := pipeline.Subscribe()
+	defer observer.Close()
+	var values []interface{}
+	for {
+		select {
+		case <-subscriber.C():
+			values = append(values, subscriber.Value())
+			continue
+		case <-subscriber.Done():
+		case <-time.After(time.Second):
+			t.Fatal("timed out")
+		}
+		break
+	}
+	if len(values) != 2 || values[0] != 150.0 || values[1] != -200.0 {
+		t.Errorf("Got %v. Expected [150 -200]", values)
+	}
+}
+
+func TestLoadJSON(t *testing.T) {
+	pipeline, err := config.Load([]byte(`{
+		"source": {"type": "http", "url": "http://localhost/metrics", "refresh": "10s", "json_path": "a.b"},
+		"filters": [
+			{"type": "MovingAverage", "window": 10},
+			{"chain": [{"type": "Sigma", "window": 50, "factor": 3}, {"type": "Mute", "duration": 60}]}
+		]
+	}`))
+	if err != nil || pipeline.Source == nil || pipeline.Filter == nil {
+		t.Errorf("Got %v. Expected valid pipeline", err)
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	source := "source:\n  type: stdin\n"
+	tests := []struct {
+		doc  string
+		path string
+	}{
+		{"filters: []", "source"},
+		{"source:\n  type: socket\n", "source.type"},
+		{"source:\n  type: tail\n", "source.path"},
+		{"source:\n  type: command\n  name: df\n  args: [-h, 1]\n", "source.args[1]"},
+		{source + "filters:\n  - type: MovingAverage\n    window: ten\n", "filters[0].window"},
+		{source + "filters:\n  - type: None\n  - type: Unknown\n", "filters[1].type"},
+		{source + "filters:\n  - switch:\n      - type: AboveFloat64\n", "filters[0].switch[0].value"},
+		{source + "filters:\n  - type: Sigma\n    windw: 10\n", "filters[0].windw"},
+		{source + "filters:\n  - type: TumblingWindow\n    size: 1m\n    aggregate: median\n", "filters[0].aggregate"},
+		{source + "filters:\n  - chain: {}\n", "filters[0].chain"},
+		{source + "filters:\n  - 42\n", "filters[0]"},
+		{"source:\n  type: http\n  url: http://localhost/\n  refresh: 0s\n", "source.refresh"},
+		{"source:\n  type: prometheus\n  url: http://localhost/metrics\n  refresh: 0\n", "source.refresh"},
+	}
+	for _, test := range tests {
+		_, err := config.Load([]byte(test.doc))
+		var cerr *config.Error
+		if !errors.As(err, &cerr) || cerr.Path != test.path {
+			t.Errorf("Got %v. Expected error at %s for %q", err, test.path, test.doc)
+		}
+	}
+
+	_, err := config.Load([]byte("source: [unclosed"))
+	if err == nil || !strings.HasPrefix(err.Error(), "config:") {
+		t.Errorf("Got %v. Expected syntax error", err)
+	}
+}
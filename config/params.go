@@ -0,0 +1,211 @@
+package config
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+//params reads the parameters of a node.
+//It records the first invalid parameter, so that a builder
+//can read all parameters and check the error once.
+type params struct {
+	base string
+	m    map[string]interface{}
+	used map[string]bool
+	err  error
+}
+
+//newParams returns the parameters of a node that must be a mapping
+func newParams(path string, node interface{}) (*params, error) {
+	m, ok := node.(map[string]interface{})
+	if !ok {
+		return nil, &Error{Path: path, Err: fmt.Errorf("expected a mapping, got %v", node)}
+	}
+	return &params{base: path, m: m, used: make(map[string]bool)}, nil
+}
+
+//path returns the path of a parameter
+func (p *params) path(name string) string {
+	return joinPath(p.base, name)
+}
+
+//fail records an error for a parameter
+func (p *params) fail(name, format string, args ...interface{}) {
+	if p.err == nil {
+		p.err = &Error{Path: p.path(name), Err: fmt.Errorf(format, args...)}
+	}
+}
+
+//value returns a parameter and marks it as used
+func (p *params) value(name string) (interface{}, bool) {
+	p.used[name] = true
+	v, ok := p.m[name]
+	if ok && v == nil {
+		return nil, false
+	}
+	return v, ok
+}
+
+//Required records an error for the parameters that are missing
+func (p *params) Required(names ...string) {
+	for _, name := range names {
+		if _, ok := p.m[name]; !ok {
+			p.fail(name, "missing parameter")
+		}
+	}
+}
+
+//String returns a string parameter or the default
+func (p *params) String(name, def string) string {
+	v, ok := p.value(name)
+	if !ok {
+		return def
+	}
+	s, ok := v.(string)
+	if !ok {
+		p.fail(name, "expected a string, got %v", v)
+	}
+	return s
+}
+
+//Float returns a numeric parameter or the default
+func (p *params) Float(name string, def float64) float64 {
+	v, ok := p.value(name)
+	if !ok {
+		return def
+	}
+	switch t := v.(type) {
+	case int:
+		return float64(t)
+	case float64:
+		return t
+	}
+	p.fail(name, "expected a number, got %v", v)
+	return def
+}
+
+//Int returns an integer parameter or the default
+func (p *params) Int(name string, def int) int {
+	v, ok := p.value(name)
+	if !ok {
+		return def
+	}
+	switch t := v.(type) {
+	case int:
+		return t
+	case float64:
+		if t == math.Trunc(t) {
+			return int(t)
+		}
+	}
+	p.fail(name, "expected an integer, got %v", v)
+	return def
+}
+
+//Bool returns a boolean parameter or the default
+func (p *params) Bool(name string, def bool) bool {
+	v, ok := p.value(name)
+	if !ok {
+		return def
+	}
+	b, ok := v.(bool)
+	if !ok {
+		p.fail(name, "expected true or false, got %v", v)
+	}
+	return b
+}
+
+//Duration returns a duration like "1m30s" or a number of seconds, or the default
+func (p *params) Duration(name string, def time.Duration) time.Duration {
+	v, ok := p.value(name)
+	if !ok {
+		return def
+	}
+	switch t := v.(type) {
+	case string:
+		d, err := time.ParseDuration(t)
+		if err != nil {
+			p.fail(name, "invalid duration %q", t)
+		}
+		return d
+	case int:
+		return time.Duration(t) * time.Second
+	case float64:
+		return time.Duration(t * float64(time.Second))
+	}
+	p.fail(name, "expected a duration, got %v", v)
+	return def
+}
+
+//PositiveDuration returns a duration that must be positive
+func (p *params) PositiveDuration(name string) time.Duration {
+	d := p.Duration(name, 0)
+	if _, ok := p.m[name]; ok && d <= 0 {
+		p.fail(name, "expected a positive duration, got %v", d)
+	}
+	return d
+}
+
+//Strings returns a list of strings
+func (p *params) Strings(name string) []string {
+	v, ok := p.value(name)
+	if !ok {
+		return nil
+	}
+	list, ok := v.([]interface{})
+	if !ok {
+		p.fail(name, "expected a list of strings, got %v", v)
+		return nil
+	}
+	strs := make([]string, len(list))
+	for i, e := range list {
+		s, ok := e.(string)
+		if !ok {
+			p.fail(fmt.Sprintf("%s[%d]", name, i), "expected a string, got %v", e)
+		}
+		strs[i] = s
+	}
+	return strs
+}
+
+//StringMap returns a mapping of strings
+func (p *params) StringMap(name string) map[string]string {
+	v, ok := p.value(name)
+	if !ok {
+		return nil
+	}
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		p.fail(name, "expected a mapping, got %v", v)
+		return nil
+	}
+	strs := make(map[string]string, len(m))
+	for key, e := range m {
+		s, ok := e.(string)
+		if !ok {
+			p.fail(name+"."+key, "expected a string, got %v", e)
+		}
+		strs[key] = s
+	}
+	return strs
+}
+
+//check returns the first error or an error for an unknown parameter
+func (p *params) check() error {
+	if p.err != nil {
+		return p.err
+	}
+	var unknown []string
+	for name := range p.m {
+		if !p.used[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return &Error{Path: p.path(unknown[0]), Err: fmt.Errorf("unknown parameter")}
+	}
+	return nil
+}
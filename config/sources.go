@@ -0,0 +1,230 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"regexp"
+
+	"github.com/konimarti/flow"
+	"github.com/konimarti/flow/filters"
+	"github.com/konimarti/flow/observer"
+)
+
+//builtinSources creates the sources of the flow package by type
+var builtinSources = map[string]func(p *params) flow.Source{
+	"stdin": func(p *params) flow.Source {
+		return &flow.Reader{R: os.Stdin, Decoder: decoder(p), Policy: policy(p)}
+	},
+	"file": func(p *params) flow.Source {
+		p.Required("path")
+		path, decoder, policy := p.String("path", ""), decoder(p), policy(p)
+		return &fileSource{path: path, source: func(r io.Reader) flow.Source {
+			return &flow.Reader{R: r, Decoder: decoder, Policy: policy}
+		}}
+	},
+	"replay": func(p *params) flow.Source {
+		p.Required("path")
+		path := p.String("path", "")
+		replay := flow.Replay{
+			TimeField:  p.String("time_field", ""),
+			ValueField: p.String("value_field", ""),
+			TimeLayout: p.String("time_layout", ""),
+			Speed:      p.Float("speed", 0),
+			Policy:     policy(p),
+		}
+		switch format := p.String("format", ""); format {
+		case "":
+		case "csv":
+			replay.Format = flow.CSV
+		case "jsonl", "json":
+			replay.Format = flow.JSONLines
+		default:
+			p.fail("format", "unknown format %q", format)
+		}
+		return &fileSource{path: path, source: func(r io.Reader) flow.Source {
+			source := replay
+			source.R = r
+			return &source
+		}}
+	},
+	"tail": func(p *params) flow.Source {
+		p.Required("path")
+		return &flow.Tail{
+			Path:    p.String("path", ""),
+			FromEnd: p.Bool("from_end", false),
+			Poll:    p.Duration("poll", 0),
+			Decoder: decoder(p),
+			Policy:  policy(p),
+		}
+	},
+	"http": func(p *params) flow.Source {
+		p.Required("url", "refresh")
+		source := &flow.HTTP{
+			URL:      p.String("url", ""),
+			Method:   p.String("method", ""),
+			Header:   header(p),
+			Refresh:  p.PositiveDuration("refresh"),
+			Timeout:  p.Duration("timeout", 0),
+			JSONPath: p.String("json_path", ""),
+			Policy:   policy(p),
+		}
+		if expr := p.String("regexp", ""); expr != "" {
+			re, err := regexp.Compile(expr)
+			if err != nil {
+				p.fail("regexp", "%v", err)
+			}
+			source.Regexp = re
+		}
+		if _, ok := p.m["decoder"]; ok {
+			source.Decoder = decoder(p)
+		}
+		return source
+	},
+	"prometheus": func(p *params) flow.Source {
+		p.Required("url", "refresh")
+		return &flow.Prometheus{
+			URL:      p.String("url", ""),
+			Header:   header(p),
+			Selector: p.String("selector", ""),
+			Refresh:  p.PositiveDuration("refresh"),
+			Timeout:  p.Duration("timeout", 0),
+			Policy:   policy(p),
+		}
+	},
+	"command": func(p *params) flow.Source {
+		p.Required("name")
+		return &flow.Command{
+			Name:    p.String("name", ""),
+			Args:    p.Strings("args"),
+			Dir:     p.String("dir", ""),
+			Refresh: p.Duration("refresh", 0),
+			Timeout: p.Duration("timeout", 0),
+			Decoder: decoder(p),
+			Policy:  policy(p),
+		}
+	},
+	"listen": func(p *params) flow.Source {
+		p.Required("address")
+		return &flow.Listener{
+			Network:  p.String("network", ""),
+			Address:  p.String("address", ""),
+			MaxConns: p.Int("max_conns", 0),
+			Decoder:  decoder(p),
+			Policy:   policy(p),
+		}
+	},
+	"listen_packet": func(p *params) flow.Source {
+		p.Required("address")
+		return &flow.PacketListener{
+			Network: p.String("network", ""),
+			Address: p.String("address", ""),
+			Decoder: decoder(p),
+			Policy:  policy(p),
+		}
+	},
+}
+
+//buildSource builds a source by type
+func buildSource(path string, node interface{}) (flow.Source, error) {
+	p, err := newParams(path, node)
+	if err != nil {
+		return nil, err
+	}
+	p.Required("type")
+	name := p.String("type", "")
+	if p.err != nil {
+		return nil, p.err
+	}
+	build, ok := builtinSources[name]
+	if !ok {
+		return nil, &Error{Path: p.path("type"), Err: fmt.Errorf("unknown source %q", name)}
+	}
+	source := build(p)
+	if err := p.check(); err != nil {
+		return nil, err
+	}
+	return source, nil
+}
+
+//decoder returns the decoder of a source: string, float, json or csv
+func decoder(p *params) flow.Decoder {
+	switch name := p.String("decoder", "string"); name {
+	case "string":
+		return flow.StringDecoder
+	case "float":
+		return flow.FloatDecoder
+	case "json":
+		return flow.JSONDecoder
+	case "csv":
+		return &flow.CSVDecoder{}
+	default:
+		p.fail("decoder", "unknown decoder %q", name)
+	}
+	return nil
+}
+
+//policy returns the error policy of a source: forward, skip or stop
+func policy(p *params) flow.ErrorPolicy {
+	switch name := p.String("errors", "forward"); name {
+	case "forward":
+		return flow.ForwardErrors
+	case "skip":
+		return flow.SkipErrors
+	case "stop":
+		return flow.StopOnError
+	default:
+		p.fail("errors", "unknown error policy %q", name)
+	}
+	return flow.ForwardErrors
+}
+
+//header returns the HTTP headers of a source
+func header(p *params) http.Header {
+	m := p.StringMap("headers")
+	if m == nil {
+		return nil
+	}
+	h := make(http.Header)
+	for key, value := range m {
+		h.Set(key, value)
+	}
+	return h
+}
+
+//fileSource opens a file when the flow starts and closes it when the flow ends
+type fileSource struct {
+	path   string
+	source func(io.Reader) flow.Source
+}
+
+//Run opens the file and starts the flow
+func (f *fileSource) Run(nf filters.Filter) observer.Observer {
+	return f.RunContext(context.Background(), nf)
+}
+
+//RunContext opens the file and starts the flow until the context is done.
+//The flow ends with the error if the file cannot be opened.
+func (f *fileSource) RunContext(ctx context.Context, nf filters.Filter) observer.Observer {
+	file, err := os.Open(f.path)
+	if err != nil {
+		return flow.NewWithContext(ctx, nf, &flow.Reader{R: errReader{err}})
+	}
+	o := flow.NewWithContext(ctx, nf, f.source(file))
+	go func() {
+		<-o.Control().E
+		file.Close()
+	}()
+	return o
+}
+
+//errReader is a reader that fails
+type errReader struct {
+	err error
+}
+
+func (e errReader) Read(p []byte) (int, error) {
+	return 0, e.err
+}
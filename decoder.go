@@ -0,0 +1,91 @@
+package flow
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"strconv"
+	"strings"
+)
+
+//Decoder decodes a record (e.g. a line) into a value for the flow
+type Decoder interface {
+	Decode(record []byte) (interface{}, error)
+}
+
+//DecoderFunc is an adapter to use ordinary functions as decoders
+type DecoderFunc func([]byte) (interface{}, error)
+
+//Decode calls f(record)
+func (f DecoderFunc) Decode(record []byte) (interface{}, error) {
+	return f(record)
+}
+
+//StringDecoder returns the record as string
+var StringDecoder Decoder = DecoderFunc(func(record []byte) (interface{}, error) {
+	return string(record), nil
+})
+
+//FloatDecoder parses the record as float64
+var FloatDecoder Decoder = DecoderFunc(func(record []byte) (interface{}, error) {
+	return strconv.ParseFloat(strings.TrimSpace(string(record)), 64)
+})
+
+//JSONDecoder parses the record as JSON.
+//Objects are returned as map[string]interface{}.
+var JSONDecoder Decoder = DecoderFunc(func(record []byte) (interface{}, error) {
+	var v interface{}
+	d := json.NewDecoder(bytes.NewReader(record))
+	d.UseNumber()
+	if err := d.Decode(&v); err != nil {
+		return nil, err
+	}
+	return normalize(v), nil
+})
+
+//normalize converts json.Number to float64 so that
+//the values can be processed by the numeric filters
+func normalize(v interface{}) interface{} {
+	switch t := v.(type) {
+	case json.Number:
+		f, err := t.Float64()
+		if err != nil {
+			return t.String()
+		}
+		return f
+	case map[string]interface{}:
+		for k, e := range t {
+			t[k] = normalize(e)
+		}
+	case []interface{}:
+		for i, e := range t {
+			t[i] = normalize(e)
+		}
+	}
+	return v
+}
+
+//CSVDecoder parses the record as a row of comma-separated values
+//and returns the fields as []string
+type CSVDecoder struct {
+	//Comma is the field delimiter (default ',')
+	Comma rune
+}
+
+//Decode parses a CSV row
+func (c *CSVDecoder) Decode(record []byte) (interface{}, error) {
+	r := csv.NewReader(bytes.NewReader(record))
+	if c.Comma != 0 {
+		r.Comma = c.Comma
+	}
+	r.FieldsPerRecord = -1
+	return r.Read()
+}
+
+//decoderOr returns d or the StringDecoder if d is nil
+func decoderOr(d Decoder) Decoder {
+	if d == nil {
+		return StringDecoder
+	}
+	return d
+}
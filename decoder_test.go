@@ -0,0 +1,38 @@
+package flow_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/konimarti/flow"
+)
+
+func TestDecoders(t *testing.T) {
+	var config = []struct {
+		Name    string
+		Decoder flow.Decoder
+		Record  string
+		Want    interface{}
+		Err     bool
+	}{
+		{"String", flow.StringDecoder, "hello world", "hello world", false},
+		{"Float", flow.FloatDecoder, " 3.5 ", 3.5, false},
+		{"FloatError", flow.FloatDecoder, "abc", nil, true},
+		{"CSV", &flow.CSVDecoder{}, `a,"b,c",1.5`, []string{"a", "b,c", "1.5"}, false},
+		{"CSVSemicolon", &flow.CSVDecoder{Comma: ';'}, "a;b", []string{"a", "b"}, false},
+		{"JSON", flow.JSONDecoder, `{"name":"t1","value":21.5,"tags":[1,2]}`,
+			map[string]interface{}{"name": "t1", "value": 21.5, "tags": []interface{}{1.0, 2.0}}, false},
+		{"JSONError", flow.JSONDecoder, `{"name":`, nil, true},
+	}
+
+	for _, cfg := range config {
+		v, err := cfg.Decoder.Decode([]byte(cfg.Record))
+		if (err != nil) != cfg.Err {
+			t.Errorf("%s: unexpected error %v", cfg.Name, err)
+			continue
+		}
+		if !cfg.Err && !reflect.DeepEqual(v, cfg.Want) {
+			t.Errorf("%s: Got %#v. Expected %#v", cfg.Name, v, cfg.Want)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package flow
+
+import (
+	"context"
+	"time"
+
+	"github.com/konimarti/flow/clock"
+	"github.com/konimarti/flow/filters"
+	"github.com/konimarti/flow/observer"
+)
+
+//ErrorPolicy defines how a flow handles errors of sources and filters
+type ErrorPolicy int
+
+const (
+	//ForwardErrors sends errors to the error channel of the observer and continues
+	ForwardErrors ErrorPolicy = iota
+	//SkipErrors drops the failing value and continues
+	SkipErrors
+	//StopOnError ends the flow; the error is returned by the subscribers' Err
+	StopOnError
+)
+
+//FuncErr implements the Source interface and regularly calls a function that can fail
+type FuncErr struct {
+	Fn      func() (interface{}, error)
+	Refresh time.Duration
+	Policy  ErrorPolicy
+}
+
+//Run calls the given function in regular intervals
+func (f *FuncErr) Run(nf filters.Filter) observer.Observer {
+	return f.RunContext(context.Background(), nf)
+}
+
+//RunContext calls the given function in regular intervals until the context is done
+func (f *FuncErr) RunContext(ctx context.Context, nf filters.Filter) observer.Observer {
+	return f.job(nf).run(ctx)
+}
+
+//job returns the flow that calls the given function in regular intervals
+func (f *FuncErr) job(nf filters.Filter) job {
+	return job{nf: nf, policy: f.Policy, produce: func(ctx context.Context, emit emitFunc) error {
+		ticker := clock.FromContext(ctx).NewTicker(f.Refresh)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C():
+				if !emit(f.Fn()) {
+					return nil
+				}
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+	}}
+}
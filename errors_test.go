@@ -0,0 +1,119 @@
+package flow_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/konimarti/flow"
+	"github.com/konimarti/flow/filters"
+)
+
+func failing() func() (interface{}, error) {
+	var i int
+	return func() (interface{}, error) {
+		i++
+		if i%2 == 0 {
+			return nil, errors.New("read failed")
+		}
+		return float64(i), nil
+	}
+}
+
+func TestFuncErrForward(t *testing.T) {
+	observer := flow.New(&filters.None{}, &flow.FuncErr{
+		Fn:      failing(),
+		Refresh: 10 * time.Millisecond,
+		Policy:  flow.ForwardErrors,
+	})
+	defer observer.Close()
+
+	select {
+	case <-time.After(1 * time.Second):
+		t.Fatal("timed out waiting for error")
+	case err := <-observer.Errors():
+		if err.Error() != "read failed" {
+			t.Errorf("Got %v. Expected read failed", err)
+		}
+	}
+}
+
+func TestFuncErrSkip(t *testing.T) {
+	observer := flow.New(&filters.None{}, &flow.FuncErr{
+		Fn:      failing(),
+		Refresh: 10 * time.Millisecond,
+		Policy:  flow.SkipErrors,
+	})
+	subscriber := observer.Subscribe()
+
+	for _, want := range []float64{1.0, 3.0, 5.0} {
+		select {
+		case <-time.After(1 * time.Second):
+			t.Fatal("timed out")
+		case <-subscriber.C():
+			if v := subscriber.Value(); v != want {
+				t.Errorf("Got %v. Expected %v", v, want)
+			}
+		}
+	}
+	observer.Close()
+
+	for err := range observer.Errors() {
+		t.Errorf("error should have been skipped: %v", err)
+	}
+}
+
+func TestFuncErrStop(t *testing.T) {
+	observer := flow.New(&filters.None{}, &flow.FuncErr{
+		Fn:      failing(),
+		Refresh: 10 * time.Millisecond,
+		Policy:  flow.StopOnError,
+	})
+	defer observer.Close()
+	subscriber := observer.Subscribe()
+
+	for {
+		select {
+		case <-time.After(1 * time.Second):
+			t.Fatal("timed out")
+		case <-subscriber.C():
+			subscriber.Value()
+			continue
+		case <-subscriber.Done():
+			if err := subscriber.Err(); err == nil || err.Error() != "read failed" {
+				t.Errorf("Got %v. Expected read failed", err)
+			}
+		}
+		return
+	}
+}
+
+func TestFilterErrors(t *testing.T) {
+	ch := make(chan interface{})
+	observer := flow.New(filters.NewErrChain(&parse{}), &flow.Chan{Ch: ch})
+	defer observer.Close()
+
+	go func() { ch <- "not a number" }()
+
+	select {
+	case <-time.After(1 * time.Second):
+		t.Fatal("timed out waiting for error")
+	case err := <-observer.Errors():
+		if err == nil {
+			t.Error("expected filter error")
+		}
+	}
+}
+
+type parse struct{}
+
+func (p *parse) Check(v interface{}) (bool, error) {
+	if _, ok := v.(float64); !ok {
+		return false, errors.New("not a float64")
+	}
+	return true, nil
+}
+
+func (p *parse) Update(v interface{}) (interface{}, error) {
+	return v, nil
+}
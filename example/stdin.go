@@ -0,0 +1,32 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/konimarti/flow"
+	"github.com/konimarti/flow/filters"
+)
+
+// Reads numbers from stdin and prints the moving average, e.g.
+// seq 1 100 | go run stdin.go
+func main() {
+	observer, results := flow.Subscribe(
+		&filters.MovingAverage{Window: 10},
+		&flow.Reader{
+			R:       os.Stdin,
+			Decoder: flow.FloatDecoder,
+			Policy:  flow.SkipErrors,
+		},
+	)
+	defer observer.Close()
+
+	for {
+		select {
+		case <-results.C():
+			fmt.Println(results.Value())
+		case <-results.Done():
+			return
+		}
+	}
+}
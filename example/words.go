@@ -12,14 +12,14 @@ func main() {
 	ch := make(chan interface{})
 
 	// stream strings through the channel to the filters
+	// and close the channel to end the stream
 	input := []string{"Alabama", "Alaska", "Arizona", "Arkensas", "California", "Colorado"}
-	done := make(chan struct{})
 	go func() {
 		for _, word := range input {
 			fmt.Println("entering:", word)
 			ch <- word
 		}
-		done <- struct{}{}
+		close(ch)
 	}()
 
 	// create channel-based flow and set an OnValue trigger.
@@ -32,7 +32,10 @@ func main() {
 		select {
 		case <-results.C():
 			fmt.Println("Found:", results.Value())
-		case <-done:
+		case <-results.Done():
+			if err := results.Err(); err != nil {
+				fmt.Println("Error:", err)
+			}
 			return
 		}
 	}
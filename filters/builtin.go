@@ -0,0 +1,244 @@
+package filters
+
+import (
+	"fmt"
+	"os"
+)
+
+func init() {
+	for _, spec := range builtin {
+		DefaultRegistry.MustRegister(spec)
+	}
+}
+
+//builtin are the specs of the filters of this package
+var builtin = []Spec{
+	{
+		Name: "None",
+		Doc:  "Forwards all values.",
+		New: func(p Params) (Filter, error) {
+			return &None{}, nil
+		},
+	},
+	{
+		Name: "Sink",
+		Doc:  "Never forwards any values.",
+		New: func(p Params) (Filter, error) {
+			return &Sink{}, nil
+		},
+	},
+	{
+		Name: "Unwrap",
+		Doc:  "Removes the Event envelope and forwards the value.",
+		New: func(p Params) (Filter, error) {
+			return &Unwrap{}, nil
+		},
+	},
+	{
+		Name:   "Print",
+		Doc:    "Prints the values to stdout and forwards them.",
+		Params: []Param{{Name: "prefix", Type: StringParam, Default: ""}},
+		New: func(p Params) (Filter, error) {
+			return &Print{Writer: os.Stdout, Prefix: p.String("prefix")}, nil
+		},
+	},
+	{
+		Name:   "Mute",
+		Doc:    "Blocks values within the duration after a forwarded value.",
+		Params: []Param{{Name: "duration", Type: DurationParam, Required: true}},
+		New: func(p Params) (Filter, error) {
+			return &Mute{Duration: p.Duration("duration")}, nil
+		},
+	},
+	{
+		Name:   "OnChange",
+		Doc:    "Forwards a value when it changes.",
+		Params: []Param{{Name: "value", Type: AnyParam, Doc: "initial value"}},
+		New: func(p Params) (Filter, error) {
+			return &OnChange{Value: p["value"]}, nil
+		},
+	},
+	{
+		Name:   "OnValue",
+		Doc:    "Forwards the value when it equals the given value.",
+		Params: []Param{{Name: "value", Type: AnyParam, Required: true}},
+		New: func(p Params) (Filter, error) {
+			return &OnValue{Value: p["value"]}, nil
+		},
+	},
+	{
+		Name:   "OnRisingFlank",
+		Doc:    "Forwards a value when it is larger than the previous value.",
+		Params: []Param{{Name: "value", Type: AnyParam, Doc: "initial value"}},
+		New: func(p Params) (Filter, error) {
+			return &OnRisingFlank{Value: p["value"]}, nil
+		},
+	},
+	{
+		Name:   "AboveFloat64",
+		Doc:    "Forwards values above the threshold.",
+		Params: []Param{{Name: "value", Type: FloatParam, Required: true, Doc: "threshold"}},
+		New: func(p Params) (Filter, error) {
+			return &AboveFloat64{Value: p.Float("value")}, nil
+		},
+	},
+	{
+		Name:   "BelowFloat64",
+		Doc:    "Forwards values below the threshold.",
+		Params: []Param{{Name: "value", Type: FloatParam, Required: true, Doc: "threshold"}},
+		New: func(p Params) (Filter, error) {
+			return &BelowFloat64{Value: p.Float("value")}, nil
+		},
+	},
+	{
+		Name: "Expr",
+		Doc:  "Forwards the values for which the check expression is true and transforms them with the update expression.",
+		Params: []Param{
+			{Name: "check", Type: StringParam, Doc: "predicate, e.g. abs(x - prev) > 3"},
+			{Name: "update", Type: StringParam, Doc: "transformation, e.g. x * 1.8 + 32"},
+		},
+		New: func(p Params) (Filter, error) {
+			return NewExpr(p.String("check"), p.String("update"))
+		},
+	},
+	{
+		Name:   "MovingAverage",
+		Doc:    "Returns the moving average of the last values.",
+		Params: []Param{{Name: "window", Type: IntParam, Default: 10, Doc: "number of values"}},
+		New: func(p Params) (Filter, error) {
+			window, err := positive("MovingAverage", p, "window")
+			if err != nil {
+				return nil, err
+			}
+			return &MovingAverage{Window: window}, nil
+		},
+	},
+	{
+		Name: "Sigma",
+		Doc:  "Forwards values that are more than factor standard deviations away from the mean.",
+		Params: []Param{
+			{Name: "window", Type: IntParam, Default: 50, Doc: "number of values"},
+			{Name: "factor", Type: FloatParam, Default: 3.0, Doc: "number of standard deviations"},
+		},
+		New: func(p Params) (Filter, error) {
+			window, err := positive("Sigma", p, "window")
+			if err != nil {
+				return nil, err
+			}
+			return &Sigma{Window: window, Factor: p.Float("factor")}, nil
+		},
+	},
+	{
+		Name:   "Stddev",
+		Doc:    "Returns the standard deviation of the last values.",
+		Params: []Param{{Name: "window", Type: IntParam, Default: 10, Doc: "number of values"}},
+		New: func(p Params) (Filter, error) {
+			window, err := positive("Stddev", p, "window")
+			if err != nil {
+				return nil, err
+			}
+			return &Stddev{Window: window}, nil
+		},
+	},
+	{
+		Name:   "LowPass",
+		Doc:    "Returns the exponentially smoothed values.",
+		Params: []Param{{Name: "a", Type: FloatParam, Default: 0.5, Doc: "smoothing factor in (0, 1]"}},
+		New: func(p Params) (Filter, error) {
+			a := p.Float("a")
+			if a <= 0 || a > 1 {
+				return nil, &ParamError{Filter: "LowPass", Param: "a", Err: fmt.Errorf("expected a value in (0, 1], got %v", a)}
+			}
+			return &LowPass{A: a}, nil
+		},
+	},
+	{
+		Name: "TumblingWindow",
+		Doc:  "Aggregates the values of consecutive windows.",
+		Params: append([]Param{
+			{Name: "size", Type: DurationParam, Required: true},
+		}, windowParams...),
+		New: func(p Params) (Filter, error) {
+			agg, lateness, err := windowOptions("TumblingWindow", p)
+			if err == nil {
+				err = positiveDurations("TumblingWindow", p, "size")
+			}
+			if err != nil {
+				return nil, err
+			}
+			return &TumblingWindow{Size: p.Duration("size"), Aggregate: agg, Lateness: lateness}, nil
+		},
+	},
+	{
+		Name: "SlidingWindow",
+		Doc:  "Aggregates the values of overlapping windows.",
+		Params: append([]Param{
+			{Name: "size", Type: DurationParam, Required: true},
+			{Name: "slide", Type: DurationParam, Required: true},
+		}, windowParams...),
+		New: func(p Params) (Filter, error) {
+			agg, lateness, err := windowOptions("SlidingWindow", p)
+			if err == nil {
+				err = positiveDurations("SlidingWindow", p, "size", "slide")
+			}
+			if err != nil {
+				return nil, err
+			}
+			return &SlidingWindow{Size: p.Duration("size"), Slide: p.Duration("slide"), Aggregate: agg, Lateness: lateness}, nil
+		},
+	},
+	{
+		Name: "SessionWindow",
+		Doc:  "Aggregates the values of sessions that end after a gap.",
+		Params: []Param{
+			{Name: "gap", Type: DurationParam, Required: true},
+			windowParams[0],
+		},
+		New: func(p Params) (Filter, error) {
+			agg, _, err := windowOptions("SessionWindow", p)
+			if err == nil {
+				err = positiveDurations("SessionWindow", p, "gap")
+			}
+			if err != nil {
+				return nil, err
+			}
+			return &SessionWindow{Gap: p.Duration("gap"), Aggregate: agg}, nil
+		},
+	},
+}
+
+//windowParams are the common parameters of the time windows
+var windowParams = []Param{
+	{Name: "aggregate", Type: StringParam, Default: "mean", Doc: "sum, mean, min, max, count or a percentile like p95"},
+	{Name: "delay", Type: DurationParam, Doc: "delay of the watermark"},
+	{Name: "allowed_lateness", Type: DurationParam, Doc: "time in which late values update a window"},
+}
+
+//positive returns a positive IntParam
+func positive(filter string, p Params, name string) (int, error) {
+	n := p.Int(name)
+	if n <= 0 {
+		return n, &ParamError{Filter: filter, Param: name, Err: fmt.Errorf("expected a positive integer, got %d", n)}
+	}
+	return n, nil
+}
+
+//positiveDurations checks that the DurationParams are positive
+func positiveDurations(filter string, p Params, names ...string) error {
+	for _, name := range names {
+		if d := p.Duration(name); d <= 0 {
+			return &ParamError{Filter: filter, Param: name, Err: fmt.Errorf("expected a positive duration, got %v", d)}
+		}
+	}
+	return nil
+}
+
+//windowOptions returns the aggregate and the lateness of a window
+func windowOptions(filter string, p Params) (Aggregate, Lateness, error) {
+	lateness := Lateness{Delay: p.Duration("delay"), Allowed: p.Duration("allowed_lateness")}
+	agg, err := ParseAggregate(p.String("aggregate"))
+	if err != nil {
+		return nil, lateness, &ParamError{Filter: filter, Param: "aggregate", Err: err}
+	}
+	return agg, lateness, nil
+}
@@ -4,16 +4,19 @@ type chain struct {
 	fs    []Filter
 	Value interface{}
 	Flag  bool
+	err   error
 }
 
 func (c *chain) Check(v interface{}) bool {
 	c.Value = v
 	c.Flag = true
+	c.err = nil
 	for _, f := range c.fs {
 		if f.Check(c.Value) {
 			c.Value = f.Update(c.Value)
 		} else {
 			c.Flag = false
+			c.err = errOf(f)
 			break
 		}
 	}
@@ -24,6 +27,11 @@ func (c *chain) Update(v interface{}) interface{} {
 	return c.Value
 }
 
+//Err returns the error of the filter that stopped the chain, if any.
+func (c *chain) Err() error {
+	return c.err
+}
+
 //NewChain chains together filters.
 func NewChain(filters ...Filter) Filter {
 	chainedFilters := make([]Filter, 0)
@@ -0,0 +1,90 @@
+package filters
+
+// ErrFilter defines the interface for filters
+// that can fail while processing incoming data.
+type ErrFilter interface {
+	//Check should return true if the observers should be notified.
+	Check(interface{}) (bool, error)
+	//Update processes the new value. Its return value is sent to the observers
+	//and it is only called when Check returns true.
+	Update(interface{}) (interface{}, error)
+}
+
+// ErrReporter is implemented by filters that record
+// the error of the last call to Check.
+type ErrReporter interface {
+	//Err returns the error that made Check return false.
+	Err() error
+}
+
+type errAdapter struct {
+	f Filter
+}
+
+func (e *errAdapter) Check(v interface{}) (bool, error) {
+	return e.f.Check(v), nil
+}
+
+func (e *errAdapter) Update(v interface{}) (interface{}, error) {
+	return e.f.Update(v), nil
+}
+
+//NewErrFilter returns an ErrFilter for an existing Filter.
+//The returned filter never fails.
+func NewErrFilter(f Filter) ErrFilter {
+	return &errAdapter{f: f}
+}
+
+type errChain struct {
+	fs    []ErrFilter
+	value interface{}
+	err   error
+}
+
+func (c *errChain) Check(v interface{}) bool {
+	c.value = v
+	c.err = nil
+	for _, f := range c.fs {
+		ok, err := f.Check(c.value)
+		if err != nil {
+			c.err = err
+			return false
+		}
+		if !ok {
+			return false
+		}
+		c.value, err = f.Update(c.value)
+		if err != nil {
+			c.err = err
+			return false
+		}
+	}
+	return true
+}
+
+func (c *errChain) Update(v interface{}) interface{} {
+	return c.value
+}
+
+func (c *errChain) Err() error {
+	return c.err
+}
+
+//NewErrChain chains together filters that can fail and returns a Filter.
+//If a filter fails, Check returns false and the error
+//is available through the ErrReporter interface.
+func NewErrChain(filters ...ErrFilter) Filter {
+	chainedFilters := make([]ErrFilter, 0)
+	for _, f := range filters {
+		chainedFilters = append(chainedFilters, f)
+	}
+	return &errChain{fs: chainedFilters}
+}
+
+//errOf returns the error recorded by a filter, if it reports errors.
+func errOf(f Filter) error {
+	if r, ok := f.(ErrReporter); ok {
+		return r.Err()
+	}
+	return nil
+}
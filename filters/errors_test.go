@@ -0,0 +1,75 @@
+package filters_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/konimarti/flow/filters"
+)
+
+type parse struct{}
+
+func (p *parse) Check(v interface{}) (bool, error) {
+	if _, ok := v.(float64); !ok {
+		return false, errors.New("not a float64")
+	}
+	return true, nil
+}
+
+func (p *parse) Update(v interface{}) (interface{}, error) {
+	return v, nil
+}
+
+func TestErrChain(t *testing.T) {
+	chain := filters.NewErrChain(
+		&parse{},
+		filters.NewErrFilter(&filters.AboveFloat64{Value: 1.0}),
+	)
+	reporter, ok := chain.(filters.ErrReporter)
+	if !ok {
+		t.Fatal("chain should report errors")
+	}
+
+	var config = []struct {
+		Value interface{}
+		Check bool
+		Err   bool
+	}{
+		{2.0, true, false},
+		{"hello", false, true},
+		{0.5, false, false},
+	}
+
+	for _, cfg := range config {
+		if check := chain.Check(cfg.Value); check != cfg.Check {
+			t.Errorf("%v: Got %v. Expected %v.", cfg.Value, check, cfg.Check)
+		}
+		if err := reporter.Err(); (err != nil) != cfg.Err {
+			t.Errorf("%v: Got error %v.", cfg.Value, err)
+		}
+	}
+}
+
+func TestChainReportsErrors(t *testing.T) {
+	chain := filters.NewChain(
+		&filters.None{},
+		filters.NewErrChain(&parse{}),
+	)
+	if chain.Check("hello") {
+		t.Error("check should fail")
+	}
+	if err := chain.(filters.ErrReporter).Err(); err == nil {
+		t.Error("chain should report the error of the inner filter")
+	}
+
+	sw := filters.NewSwitch(
+		filters.NewErrChain(&parse{}),
+		&filters.OnValue{Value: "hello"},
+	)
+	if !sw.Check("hello") {
+		t.Error("switch should match the second filter")
+	}
+	if err := sw.(filters.ErrReporter).Err(); err != nil {
+		t.Errorf("switch should not report an error when a filter matched: %v", err)
+	}
+}
@@ -0,0 +1,95 @@
+package filters
+
+import (
+	"time"
+
+	"github.com/konimarti/flow/clock"
+)
+
+// Event is an optional envelope for values that carries
+// the time of the event, a key and metadata.
+// Time-aware filters use the event time instead of the
+// time of arrival if the value is an Event.
+type Event struct {
+	Time  time.Time
+	Key   string
+	Meta  map[string]string
+	Value interface{}
+}
+
+//ValueOf returns the value of an Event or the value itself.
+func ValueOf(v interface{}) interface{} {
+	switch e := v.(type) {
+	case Event:
+		return e.Value
+	case *Event:
+		return e.Value
+	}
+	return v
+}
+
+//TimeOf returns the time of an Event or the current time
+//if the value is not an Event or has no time.
+func TimeOf(v interface{}) time.Time {
+	return timeOf(v, clock.Real)
+}
+
+//timeOf returns the time of an Event or the current time of the clock
+func timeOf(v interface{}, c clock.Clock) time.Time {
+	var t time.Time
+	switch e := v.(type) {
+	case Event:
+		t = e.Time
+	case *Event:
+		t = e.Time
+	}
+	if t.IsZero() {
+		t = clock.Or(c).Now()
+	}
+	return t
+}
+
+// Unwrap struct implements the Filter interface.
+// It removes the Event envelope and forwards the value.
+// It can be used in front of filters that do not support events.
+type Unwrap struct {
+	Model
+}
+
+//Update returns the value of the event.
+func (u *Unwrap) Update(v interface{}) interface{} {
+	return ValueOf(v)
+}
+
+// Watermark tracks the progress of the event time.
+// The watermark lags behind the largest event time seen by Delay
+// to allow for events that arrive out of order.
+type Watermark struct {
+	Delay time.Duration
+	max   time.Time
+}
+
+//Observe advances the watermark with the time of a new event.
+func (w *Watermark) Observe(t time.Time) {
+	if t.After(w.max) {
+		w.max = t
+	}
+}
+
+//Time returns the current watermark.
+//Events before the watermark are late.
+func (w *Watermark) Time() time.Time {
+	if w.max.IsZero() {
+		return w.max
+	}
+	return w.max.Add(-w.Delay)
+}
+
+// Lateness defines how time-aware filters handle events that arrive out of order.
+// Delay holds back the watermark and Allowed is the time after the watermark
+// has passed the end of a window in which late events still update the window.
+// Events that are later than allowed are dropped.
+type Lateness struct {
+	Delay   time.Duration
+	Allowed time.Duration
+}
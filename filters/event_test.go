@@ -0,0 +1,123 @@
+package filters_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/konimarti/flow/filters"
+)
+
+var epoch = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func at(seconds float64, value float64) filters.Event {
+	return filters.Event{Time: epoch.Add(time.Duration(seconds * float64(time.Second))), Value: value}
+}
+
+func TestEventHelpers(t *testing.T) {
+	e := at(1, 2.0)
+	if filters.ValueOf(e) != 2.0 || filters.ValueOf(&e) != 2.0 || filters.ValueOf(3) != 3 {
+		t.Error("ValueOf failed")
+	}
+	if !filters.TimeOf(e).Equal(epoch.Add(time.Second)) {
+		t.Error("TimeOf should return the event time")
+	}
+	if filters.GetFloat64(e) != 2.0 {
+		t.Error("GetFloat64 should parse the value of an event")
+	}
+	u := filters.Unwrap{}
+	if u.Update(e) != 2.0 {
+		t.Error("Unwrap should remove the envelope")
+	}
+}
+
+func TestWatermark(t *testing.T) {
+	w := filters.Watermark{Delay: time.Second}
+	if !w.Time().IsZero() {
+		t.Error("watermark should be zero without events")
+	}
+	w.Observe(epoch.Add(5 * time.Second))
+	w.Observe(epoch.Add(3 * time.Second))
+	if !w.Time().Equal(epoch.Add(4 * time.Second)) {
+		t.Errorf("Got %v. Expected %v", w.Time(), epoch.Add(4*time.Second))
+	}
+}
+
+func TestMuteEventTime(t *testing.T) {
+	m := filters.Mute{Duration: 10 * time.Second}
+	checks := []bool{true, false, true}
+	for i, e := range []filters.Event{at(100, 1), at(105, 1), at(111, 1)} {
+		if m.Check(e) != checks[i] {
+			t.Errorf("event %d: Expected %v", i, checks[i])
+		}
+	}
+}
+
+type windowStep struct {
+	Event filters.Event
+	Want  []float64
+}
+
+func runWindow(t *testing.T, name string, f filters.Filter, steps []windowStep) {
+	for i, step := range steps {
+		var got []float64
+		if f.Check(step.Event) {
+			got = append(got, f.Update(step.Event).(float64))
+		}
+		if len(got) != len(step.Want) || (len(got) > 0 && got[0] != step.Want[0]) {
+			t.Errorf("%s step %d: Got %v. Expected %v", name, i, got, step.Want)
+		}
+	}
+}
+
+func TestTumblingWindowEventTime(t *testing.T) {
+	w := &filters.TumblingWindow{Size: 10 * time.Second, Aggregate: filters.Sum}
+	runWindow(t, "InOrder", w, []windowStep{
+		{at(1, 1), nil},
+		{at(5, 2), nil},
+		{at(12, 4), []float64{3}},
+		{at(35, 8), []float64{4}},
+		{at(41, 1), []float64{8}},
+	})
+}
+
+func TestTumblingWindowLateness(t *testing.T) {
+	w := &filters.TumblingWindow{
+		Size:      10 * time.Second,
+		Aggregate: filters.Sum,
+		Lateness:  filters.Lateness{Delay: 2 * time.Second, Allowed: 5 * time.Second},
+	}
+	runWindow(t, "Lateness", w, []windowStep{
+		{at(1, 1), nil},
+		{at(11, 2), nil},           // watermark 9: window [0,10) still open
+		{at(5, 4), nil},            // out of order, but not late
+		{at(13, 8), []float64{5}},  // watermark 11: window [0,10) closes
+		{at(7, 16), []float64{21}}, // late within allowed lateness: window is updated
+		{at(18, 1), nil},           // watermark 16: window [0,10) is no longer kept
+		{at(3, 32), nil},           // too late: dropped
+	})
+	if w.Late() != 1 {
+		t.Errorf("Got %d late events. Expected 1", w.Late())
+	}
+}
+
+func TestSlidingWindowEventTime(t *testing.T) {
+	w := &filters.SlidingWindow{Size: 10 * time.Second, Slide: 5 * time.Second, Aggregate: filters.Count}
+	runWindow(t, "Sliding", w, []windowStep{
+		{at(1, 1), nil},
+		{at(6, 1), []float64{1}},  // [-5,5)
+		{at(11, 1), []float64{2}}, // [0,10)
+		{at(12, 1), nil},
+		{at(16, 1), []float64{3}}, // [5,15)
+	})
+}
+
+func TestSessionWindowEventTime(t *testing.T) {
+	w := &filters.SessionWindow{Gap: 5 * time.Second, Aggregate: filters.Sum}
+	runWindow(t, "Session", w, []windowStep{
+		{at(1, 1), nil},
+		{at(4, 2), nil},
+		{at(8, 4), nil},
+		{at(20, 8), []float64{7}},
+		{at(21, 1), nil},
+	})
+}
@@ -0,0 +1,434 @@
+package filters
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/konimarti/flow/clock"
+)
+
+// Expr implements the Filter interface with expressions.
+// The Check expression is a predicate that decides if a value is forwarded
+// and the Update expression transforms the value, e.g.
+//
+//	NewExpr("abs(x - prev) > 3 && x < 100", "x * 1.8 + 32")
+//
+// The expressions support numbers, strings, true, false, nil,
+// arithmetic (+ - * / %), comparisons (== != < <= > >=), boolean logic (&& || !),
+// conditionals (c ? a : b), field access on maps and structs (x.field, x["field"]),
+// indices of slices (x[0]) and the functions abs, ceil, exp, floor, log, log10,
+// max, min, pow, round, sqrt, sin, cos and tan.
+//
+// The variables are
+//
+//	x     the value (the value of an Event)
+//	prev  the previous value (x for the first value)
+//	time  the event time in seconds since the Unix epoch (the time of the Clock for other values)
+//	key   the key of an Event
+//	meta  the metadata of an Event
+//
+// Maps, slices and structs are equal (==) if their contents are equal.
+// If the value is an Event, the transformed value is sent in a copy of the Event.
+// Evaluation errors are reported by Err.
+type Expr struct {
+	Clock  clock.Clock
+	check  node
+	update node
+	prev   interface{}
+	first  bool
+	value  interface{}
+	err    error
+}
+
+//NewExpr compiles the expressions of the predicate and the transformation.
+//An empty predicate forwards all values and an empty transformation forwards the value.
+func NewExpr(check, update string) (*Expr, error) {
+	e := &Expr{first: true}
+	var err error
+	if strings.TrimSpace(check) != "" {
+		if e.check, err = parseExpr(check); err != nil {
+			return nil, fmt.Errorf("filters: invalid expression %q: %v", check, err)
+		}
+	}
+	if strings.TrimSpace(update) != "" {
+		if e.update, err = parseExpr(update); err != nil {
+			return nil, fmt.Errorf("filters: invalid expression %q: %v", update, err)
+		}
+	}
+	return e, nil
+}
+
+//Check evaluates the predicate and the transformation.
+//It returns false if the predicate is false or the evaluation fails.
+func (e *Expr) Check(newValue interface{}) bool {
+	env := &env{x: ValueOf(newValue), t: timeOf(newValue, e.Clock)}
+	switch ev := newValue.(type) {
+	case Event:
+		env.key, env.meta = ev.Key, ev.Meta
+	case *Event:
+		env.key, env.meta = ev.Key, ev.Meta
+	}
+	env.prev = e.prev
+	if e.first {
+		env.prev, e.first = env.x, false
+	}
+	e.prev = env.x
+
+	e.err = nil
+	if e.check != nil {
+		v, err := e.check.eval(env)
+		if err != nil {
+			e.err = err
+			return false
+		}
+		ok, isBool := v.(bool)
+		if !isBool {
+			e.err = fmt.Errorf("filters: expression is %v, not a boolean", v)
+			return false
+		}
+		if !ok {
+			return false
+		}
+	}
+
+	e.value = newValue
+	if e.update != nil {
+		v, err := e.update.eval(env)
+		if err != nil {
+			e.err = err
+			return false
+		}
+		switch ev := newValue.(type) {
+		case Event:
+			ev.Value = v
+			e.value = ev
+		case *Event:
+			c := *ev
+			c.Value = v
+			e.value = &c
+		default:
+			e.value = v
+		}
+	}
+	return true
+}
+
+//Update returns the transformed value.
+func (e *Expr) Update(newValue interface{}) interface{} {
+	return e.value
+}
+
+//Err returns the error of the last evaluation.
+func (e *Expr) Err() error {
+	return e.err
+}
+
+// env holds the variables of an evaluation
+type env struct {
+	x, prev interface{}
+	t       time.Time
+	key     string
+	meta    map[string]string
+}
+
+//isVariable returns true for the names of the variables
+func isVariable(name string) bool {
+	switch name {
+	case "x", "prev", "time", "key", "meta":
+		return true
+	}
+	return false
+}
+
+// node is a compiled expression
+type node interface {
+	eval(e *env) (interface{}, error)
+}
+
+type literalNode struct {
+	v interface{}
+}
+
+func (n *literalNode) eval(e *env) (interface{}, error) {
+	return n.v, nil
+}
+
+type variableNode struct {
+	name string
+}
+
+func (n *variableNode) eval(e *env) (interface{}, error) {
+	switch n.name {
+	case "x":
+		return e.x, nil
+	case "prev":
+		return e.prev, nil
+	case "time":
+		return float64(e.t.UnixNano()) / 1e9, nil
+	case "key":
+		return e.key, nil
+	}
+	return e.meta, nil
+}
+
+type fieldNode struct {
+	x    node
+	name string
+}
+
+func (n *fieldNode) eval(e *env) (interface{}, error) {
+	v, err := n.x.eval(e)
+	if err != nil {
+		return nil, err
+	}
+	return lookupField(v, n.name)
+}
+
+type indexNode struct {
+	x, i node
+}
+
+func (n *indexNode) eval(e *env) (interface{}, error) {
+	v, err := n.x.eval(e)
+	if err != nil {
+		return nil, err
+	}
+	i, err := n.i.eval(e)
+	if err != nil {
+		return nil, err
+	}
+	if name, ok := i.(string); ok {
+		return lookupField(v, name)
+	}
+	f, ok := toFloat(i)
+	if !ok || f != math.Trunc(f) {
+		return nil, fmt.Errorf("filters: invalid index %v", i)
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array, reflect.String:
+		if f < 0 || int(f) >= rv.Len() {
+			return nil, fmt.Errorf("filters: index %v out of range", f)
+		}
+		return rv.Index(int(f)).Interface(), nil
+	}
+	return nil, fmt.Errorf("filters: cannot index %v", v)
+}
+
+//lookupField returns a field of a map or a struct.
+//The first letter of the name of a struct field is not case-sensitive.
+func lookupField(v interface{}, name string) (interface{}, error) {
+	switch m := v.(type) {
+	case map[string]interface{}:
+		if f, ok := m[name]; ok {
+			return f, nil
+		}
+		return nil, fmt.Errorf("filters: field %s not found", name)
+	case map[string]string:
+		if f, ok := m[name]; ok {
+			return f, nil
+		}
+		return nil, fmt.Errorf("filters: field %s not found", name)
+	}
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	switch rv.Kind() {
+	case reflect.Struct:
+		f := rv.FieldByName(name)
+		if !f.IsValid() && name != "" {
+			f = rv.FieldByName(strings.ToUpper(name[:1]) + name[1:])
+		}
+		if f.IsValid() && f.CanInterface() {
+			return f.Interface(), nil
+		}
+	case reflect.Map:
+		if rv.Type().Key().Kind() == reflect.String {
+			f := rv.MapIndex(reflect.ValueOf(name).Convert(rv.Type().Key()))
+			if f.IsValid() {
+				return f.Interface(), nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("filters: field %s not found in %v", name, v)
+}
+
+type unaryNode struct {
+	op string
+	x  node
+}
+
+func (n *unaryNode) eval(e *env) (interface{}, error) {
+	v, err := n.x.eval(e)
+	if err != nil {
+		return nil, err
+	}
+	if n.op == "!" {
+		b, ok := v.(bool)
+		if !ok {
+			return nil, fmt.Errorf("filters: !%v is not defined", v)
+		}
+		return !b, nil
+	}
+	f, ok := toFloat(v)
+	if !ok {
+		return nil, fmt.Errorf("filters: -%v is not defined", v)
+	}
+	return -f, nil
+}
+
+type binaryNode struct {
+	op   string
+	l, r node
+}
+
+func (n *binaryNode) eval(e *env) (interface{}, error) {
+	l, err := n.l.eval(e)
+	if err != nil {
+		return nil, err
+	}
+	if n.op == "&&" || n.op == "||" {
+		lb, ok := l.(bool)
+		if !ok {
+			return nil, fmt.Errorf("filters: %v %s is not defined", l, n.op)
+		}
+		if lb == (n.op == "||") {
+			return lb, nil
+		}
+		r, err := n.r.eval(e)
+		if err != nil {
+			return nil, err
+		}
+		rb, ok := r.(bool)
+		if !ok {
+			return nil, fmt.Errorf("filters: %s %v is not defined", n.op, r)
+		}
+		return rb, nil
+	}
+
+	r, err := n.r.eval(e)
+	if err != nil {
+		return nil, err
+	}
+	lf, lnum := toFloat(l)
+	rf, rnum := toFloat(r)
+	if lnum && rnum {
+		switch n.op {
+		case "+":
+			return lf + rf, nil
+		case "-":
+			return lf - rf, nil
+		case "*":
+			return lf * rf, nil
+		case "/":
+			return lf / rf, nil
+		case "%":
+			return math.Mod(lf, rf), nil
+		case "==":
+			return lf == rf, nil
+		case "!=":
+			return lf != rf, nil
+		case "<":
+			return lf < rf, nil
+		case "<=":
+			return lf <= rf, nil
+		case ">":
+			return lf > rf, nil
+		case ">=":
+			return lf >= rf, nil
+		}
+	}
+	ls, lstr := l.(string)
+	rs, rstr := r.(string)
+	if lstr && rstr {
+		switch n.op {
+		case "+":
+			return ls + rs, nil
+		case "<":
+			return ls < rs, nil
+		case "<=":
+			return ls <= rs, nil
+		case ">":
+			return ls > rs, nil
+		case ">=":
+			return ls >= rs, nil
+		}
+	}
+	if n.op == "==" || n.op == "!=" {
+		return reflect.DeepEqual(l, r) == (n.op == "=="), nil
+	}
+	return nil, fmt.Errorf("filters: %v %s %v is not defined", l, n.op, r)
+}
+
+type condNode struct {
+	c, a, b node
+}
+
+func (n *condNode) eval(e *env) (interface{}, error) {
+	c, err := n.c.eval(e)
+	if err != nil {
+		return nil, err
+	}
+	b, ok := c.(bool)
+	if !ok {
+		return nil, fmt.Errorf("filters: condition %v is not a boolean", c)
+	}
+	if b {
+		return n.a.eval(e)
+	}
+	return n.b.eval(e)
+}
+
+type callNode struct {
+	name string
+	fn   func([]float64) float64
+	args []node
+}
+
+func (n *callNode) eval(e *env) (interface{}, error) {
+	args := make([]float64, len(n.args))
+	for i, arg := range n.args {
+		v, err := arg.eval(e)
+		if err != nil {
+			return nil, err
+		}
+		f, ok := toFloat(v)
+		if !ok {
+			return nil, fmt.Errorf("filters: %s(%v) is not defined", n.name, v)
+		}
+		args[i] = f
+	}
+	return n.fn(args), nil
+}
+
+// function is a math function with a fixed number of arguments (or any if negative)
+type function struct {
+	arity int
+	fn    func([]float64) float64
+}
+
+func mathFunc(fn func(float64) float64) function {
+	return function{1, func(args []float64) float64 { return fn(args[0]) }}
+}
+
+//functions are the functions of the expressions
+var functions = map[string]function{
+	"abs":   mathFunc(math.Abs),
+	"ceil":  mathFunc(math.Ceil),
+	"exp":   mathFunc(math.Exp),
+	"floor": mathFunc(math.Floor),
+	"log":   mathFunc(math.Log),
+	"log10": mathFunc(math.Log10),
+	"round": mathFunc(math.Round),
+	"sqrt":  mathFunc(math.Sqrt),
+	"sin":   mathFunc(math.Sin),
+	"cos":   mathFunc(math.Cos),
+	"tan":   mathFunc(math.Tan),
+	"pow":   {2, func(args []float64) float64 { return math.Pow(args[0], args[1]) }},
+	"min":   {-1, Min},
+	"max":   {-1, Max},
+}
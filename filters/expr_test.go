@@ -0,0 +1,151 @@
+package filters_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/konimarti/flow/clock"
+	"github.com/konimarti/flow/filters"
+)
+
+type reading struct {
+	Value float64
+	Unit  string
+}
+
+func TestExprEval(t *testing.T) {
+	tests := []struct {
+		expr  string
+		value interface{}
+		want  interface{}
+	}{
+		{"1 + 2 * 3 - 4 / 2", 0.0, 5.0},
+		{"(1 + 2) * 3 % 4", 0.0, 1.0},
+		{"-x + 1", 2.0, -1.0},
+		{"x > 1 && x < 3 || x == 10", 2.0, true},
+		{"!(x >= 2)", 2.0, false},
+		{"x == 'on' ? 1 : 0", "on", 1.0},
+		{`x + "!"`, "hi", "hi!"},
+		{"abs(x) + sqrt(16) + pow(2, 3) + max(1, x, 3) + min(4, 5)", -5.0, 24.0},
+		{"round(1.5) + floor(1.5) + ceil(1.2) + log(exp(1))", 0.0, 6.0},
+		{"x.value * 2", map[string]interface{}{"value": 21.0}, 42.0},
+		{`x["a b"]`, map[string]interface{}{"a b": 1.0}, 1.0},
+		{"x.Value > 20 && x.unit == 'C'", reading{21, "C"}, true},
+		{"x.value", &reading{Value: 3}, 3.0},
+		{"x[0] - x[1]", []interface{}{10.0, 4}, 6.0},
+		{"x == nil", nil, true},
+		{"x != 1e3", 1000, false},
+	}
+	for _, test := range tests {
+		f, err := filters.NewExpr("", test.expr)
+		if err != nil {
+			t.Errorf("%s: %v", test.expr, err)
+			continue
+		}
+		if !f.Check(test.value) {
+			t.Errorf("%s: %v", test.expr, f.Err())
+			continue
+		}
+		if v := f.Update(test.value); v != test.want {
+			t.Errorf("%s: got %v. Expected %v", test.expr, v, test.want)
+		}
+	}
+}
+
+func TestExprCheck(t *testing.T) {
+	f, err := filters.NewExpr("abs(x - prev) > 3 && x < 100", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var forwarded []float64
+	for _, v := range []float64{10, 11, 20, 21, 150, 15} {
+		if f.Check(v) {
+			forwarded = append(forwarded, f.Update(v).(float64))
+		}
+	}
+	if len(forwarded) != 2 || forwarded[0] != 20 || forwarded[1] != 15 {
+		t.Errorf("Got %v. Expected [20 15]", forwarded)
+	}
+}
+
+func TestExprEvent(t *testing.T) {
+	f, err := filters.NewExpr("meta.sensor == 'a' && key == 'temp' && time == 60", "x * 1.8 + 32")
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := filters.Event{Time: time.Unix(60, 0), Key: "temp", Meta: map[string]string{"sensor": "a"}, Value: 100.0}
+	if !f.Check(e) {
+		t.Fatalf("Expected event to pass: %v", f.Err())
+	}
+	if v := f.Update(e).(filters.Event); v.Value != 212.0 || v.Key != "temp" {
+		t.Errorf("Got %+v. Expected transformed event", v)
+	}
+}
+
+func TestExprClock(t *testing.T) {
+	f, err := filters.NewExpr("time == 120", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Clock = clock.NewManual(time.Unix(120, 0))
+	if !f.Check(1.0) {
+		t.Errorf("Expected the time of the clock: %v", f.Err())
+	}
+}
+
+func TestExprEqual(t *testing.T) {
+	type wrapper struct {
+		V interface{}
+	}
+	f, err := filters.NewExpr("x == prev", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range []interface{}{wrapper{V: map[string]int{"a": 1}}, wrapper{V: map[string]int{"a": 1}}} {
+		if !f.Check(v) {
+			t.Errorf("Expected equal values: %v", f.Err())
+		}
+	}
+	if f.Check(wrapper{V: map[string]int{"a": 2}}) {
+		t.Error("Expected different values")
+	}
+}
+
+func TestExprErrors(t *testing.T) {
+	for _, expr := range []string{"x +", "(x", "foo(x)", "y > 1", "abs(1, 2)", "x @ 1", "'open", "x.", "x ? 1"} {
+		if _, err := filters.NewExpr(expr, ""); err == nil {
+			t.Errorf("%s: expected compile error", expr)
+		}
+	}
+
+	f, _ := filters.NewExpr("x.missing > 1", "")
+	if f.Check(map[string]interface{}{}) || f.Err() == nil {
+		t.Error("Expected error for missing field")
+	}
+	f, _ = filters.NewExpr("x + 1", "")
+	if f.Check(1.0) || f.Err() == nil {
+		t.Error("Expected error for predicate that is not a boolean")
+	}
+	f, _ = filters.NewExpr("", "x / 0")
+	if !f.Check(1.0) || !math.IsInf(f.Update(1.0).(float64), 1) {
+		t.Error("Expected division by zero to be +Inf")
+	}
+}
+
+func TestExprChain(t *testing.T) {
+	f, err := filters.DefaultRegistry.New("Expr", map[string]interface{}{"check": "x > 1", "update": "x * 10"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	chain := filters.NewChain(f, &filters.MovingAverage{Window: 2})
+	if chain.Check(1.0) {
+		t.Error("Expected 1 to be filtered")
+	}
+	if err := chain.(filters.ErrReporter).Err(); err != nil {
+		t.Errorf("Got %v. Expected no error", err)
+	}
+	if !chain.Check(2.0) || chain.Update(2.0) != 20.0 {
+		t.Error("Expected 2 to be transformed to 20")
+	}
+}
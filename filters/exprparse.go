@@ -0,0 +1,303 @@
+package filters
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// token is a token of an expression
+type token struct {
+	kind byte // 'n' number, 's' string, 'i' identifier, 'o' operator, 0 end
+	text string
+	pos  int
+}
+
+//operators of the expression language; longer operators first
+var operators = []string{"||", "&&", "==", "!=", "<=", ">=", "<", ">", "+", "-", "*", "/", "%", "!", "(", ")", ",", ".", "[", "]", "?", ":"}
+
+//tokenize splits an expression into tokens
+func tokenize(src string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(src); {
+		c := rune(src[i])
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case unicode.IsDigit(c) || (c == '.' && i+1 < len(src) && unicode.IsDigit(rune(src[i+1]))):
+			start := i
+			for i < len(src) && (unicode.IsDigit(rune(src[i])) || src[i] == '.' ||
+				src[i] == 'e' || src[i] == 'E' ||
+				((src[i] == '+' || src[i] == '-') && (src[i-1] == 'e' || src[i-1] == 'E'))) {
+				i++
+			}
+			tokens = append(tokens, token{'n', src[start:i], start})
+		case c == '_' || unicode.IsLetter(c):
+			start := i
+			for i < len(src) && (src[i] == '_' || unicode.IsLetter(rune(src[i])) || unicode.IsDigit(rune(src[i]))) {
+				i++
+			}
+			tokens = append(tokens, token{'i', src[start:i], start})
+		case c == '"' || c == '\'':
+			start := i
+			for i++; i < len(src) && rune(src[i]) != c; i++ {
+				if src[i] == '\\' {
+					i++
+				}
+			}
+			if i >= len(src) {
+				return nil, fmt.Errorf("unterminated string at %d", start)
+			}
+			i++
+			text := src[start:i]
+			if c == '\'' {
+				text = `"` + strings.ReplaceAll(text[1:len(text)-1], `"`, `\"`) + `"`
+			}
+			s, err := strconv.Unquote(text)
+			if err != nil {
+				return nil, fmt.Errorf("invalid string at %d", start)
+			}
+			tokens = append(tokens, token{'s', s, start})
+		default:
+			op := ""
+			for _, o := range operators {
+				if strings.HasPrefix(src[i:], o) {
+					op = o
+					break
+				}
+			}
+			if op == "" {
+				return nil, fmt.Errorf("unexpected %q at %d", c, i)
+			}
+			tokens = append(tokens, token{'o', op, i})
+			i += len(op)
+		}
+	}
+	return append(tokens, token{0, "", len(src)}), nil
+}
+
+// parser is a recursive descent parser for expressions
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+//parseExpr compiles the source of an expression
+func parseExpr(src string) (node, error) {
+	tokens, err := tokenize(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	n, err := p.ternary()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != 0 {
+		return nil, fmt.Errorf("unexpected %q at %d", t.text, t.pos)
+	}
+	return n, nil
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != 0 {
+		p.pos++
+	}
+	return t
+}
+
+//accept consumes the operator if it is next
+func (p *parser) accept(ops ...string) (string, bool) {
+	t := p.peek()
+	if t.kind != 'o' {
+		return "", false
+	}
+	for _, op := range ops {
+		if t.text == op {
+			p.pos++
+			return op, true
+		}
+	}
+	return "", false
+}
+
+func (p *parser) expect(op string) error {
+	if _, ok := p.accept(op); !ok {
+		t := p.peek()
+		if t.kind == 0 {
+			return fmt.Errorf("expected %q at end", op)
+		}
+		return fmt.Errorf("expected %q at %d, got %q", op, t.pos, t.text)
+	}
+	return nil
+}
+
+func (p *parser) ternary() (node, error) {
+	c, err := p.binary(0)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := p.accept("?"); !ok {
+		return c, nil
+	}
+	a, err := p.ternary()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect(":"); err != nil {
+		return nil, err
+	}
+	b, err := p.ternary()
+	if err != nil {
+		return nil, err
+	}
+	return &condNode{c, a, b}, nil
+}
+
+//precedence lists the binary operators from the lowest to the highest precedence
+var precedence = [][]string{
+	{"||"},
+	{"&&"},
+	{"==", "!="},
+	{"<", "<=", ">", ">="},
+	{"+", "-"},
+	{"*", "/", "%"},
+}
+
+func (p *parser) binary(level int) (node, error) {
+	if level == len(precedence) {
+		return p.unary()
+	}
+	l, err := p.binary(level + 1)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op, ok := p.accept(precedence[level]...)
+		if !ok {
+			return l, nil
+		}
+		r, err := p.binary(level + 1)
+		if err != nil {
+			return nil, err
+		}
+		l = &binaryNode{op, l, r}
+	}
+}
+
+func (p *parser) unary() (node, error) {
+	if op, ok := p.accept("!", "-"); ok {
+		x, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return &unaryNode{op, x}, nil
+	}
+	return p.postfix()
+}
+
+func (p *parser) postfix() (node, error) {
+	x, err := p.primary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		if _, ok := p.accept("."); ok {
+			t := p.next()
+			if t.kind != 'i' {
+				return nil, fmt.Errorf("expected field name at %d", t.pos)
+			}
+			x = &fieldNode{x, t.text}
+			continue
+		}
+		if _, ok := p.accept("["); ok {
+			i, err := p.ternary()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect("]"); err != nil {
+				return nil, err
+			}
+			x = &indexNode{x, i}
+			continue
+		}
+		return x, nil
+	}
+}
+
+func (p *parser) primary() (node, error) {
+	t := p.next()
+	switch t.kind {
+	case 'n':
+		f, err := strconv.ParseFloat(t.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q at %d", t.text, t.pos)
+		}
+		return &literalNode{f}, nil
+	case 's':
+		return &literalNode{t.text}, nil
+	case 'i':
+		switch t.text {
+		case "true":
+			return &literalNode{true}, nil
+		case "false":
+			return &literalNode{false}, nil
+		case "nil":
+			return &literalNode{nil}, nil
+		}
+		if _, ok := p.accept("("); ok {
+			return p.call(t)
+		}
+		if !isVariable(t.text) {
+			return nil, fmt.Errorf("unknown variable %s at %d", t.text, t.pos)
+		}
+		return &variableNode{t.text}, nil
+	case 'o':
+		if t.text == "(" {
+			x, err := p.ternary()
+			if err != nil {
+				return nil, err
+			}
+			return x, p.expect(")")
+		}
+	case 0:
+		return nil, fmt.Errorf("unexpected end")
+	}
+	return nil, fmt.Errorf("unexpected %q at %d", t.text, t.pos)
+}
+
+func (p *parser) call(name token) (node, error) {
+	fn, ok := functions[name.text]
+	if !ok {
+		return nil, fmt.Errorf("unknown function %s at %d", name.text, name.pos)
+	}
+	var args []node
+	if _, ok := p.accept(")"); !ok {
+		for {
+			arg, err := p.ternary()
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, arg)
+			if _, ok := p.accept(","); !ok {
+				break
+			}
+		}
+		if err := p.expect(")"); err != nil {
+			return nil, err
+		}
+	}
+	if fn.arity >= 0 && len(args) != fn.arity {
+		return nil, fmt.Errorf("%s expects %d arguments, got %d", name.text, fn.arity, len(args))
+	}
+	if fn.arity < 0 && len(args) == 0 {
+		return nil, fmt.Errorf("%s expects arguments", name.text)
+	}
+	return &callNode{name.text, fn.fn, args}, nil
+}
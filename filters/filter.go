@@ -5,6 +5,8 @@ import (
 	"io"
 	"math"
 	"time"
+
+	"github.com/konimarti/flow/clock"
 )
 
 // Filter defines the interface that
@@ -52,15 +54,17 @@ func (s *Sink) Check(newValue interface{}) bool {
 // Mute struct implements the Filter interface.
 // It blocks the forwarding of values within a
 // predefined time duration.
+// The event time is used for values of type Event.
 type Mute struct {
 	Duration time.Duration
+	Clock    clock.Clock
 	previous time.Time
 	Model
 }
 
-//Check always returns true.
+//Check returns false if the previous value was forwarded within the duration.
 func (m *Mute) Check(newValue interface{}) bool {
-	t := time.Now()
+	t := timeOf(newValue, m.Clock)
 	if t.Sub(m.previous) < m.Duration {
 		return false
 	}
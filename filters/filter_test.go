@@ -7,6 +7,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/konimarti/flow/clock"
 	"github.com/konimarti/flow/filters"
 )
 
@@ -260,11 +261,12 @@ func TestMute(t *testing.T) {
 
 	for _, v := range values {
 		//new trigger
-		trig := filters.Mute{Duration: 100 * time.Millisecond}
+		c := clock.NewManual(time.Now())
+		trig := filters.Mute{Duration: 100 * time.Millisecond, Clock: c}
 
 		b1 := trig.Check(v)
 		b2 := trig.Check(v)
-		time.Sleep(100 * time.Millisecond)
+		c.Advance(100 * time.Millisecond)
 		b3 := trig.Check(v)
 		if b1 != true {
 			t.Error("first check should be true")
@@ -0,0 +1,311 @@
+package filters
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ParamType is the type of a filter parameter.
+type ParamType int
+
+const (
+	//FloatParam is a float64
+	FloatParam ParamType = iota
+	//IntParam is an int
+	IntParam
+	//StringParam is a string
+	StringParam
+	//BoolParam is a bool
+	BoolParam
+	//DurationParam is a time.Duration given as "1m30s" or as number of seconds
+	DurationParam
+	//AnyParam is a value of any type that is passed on as is
+	AnyParam
+)
+
+func (t ParamType) String() string {
+	switch t {
+	case FloatParam:
+		return "float"
+	case IntParam:
+		return "int"
+	case StringParam:
+		return "string"
+	case BoolParam:
+		return "bool"
+	case DurationParam:
+		return "duration"
+	}
+	return "any"
+}
+
+// Param describes a parameter of a filter.
+type Param struct {
+	Name     string
+	Type     ParamType
+	Default  interface{}
+	Required bool
+	Doc      string
+}
+
+// Params are the values of the parameters of a filter.
+// They are converted to the types of the schema before they are passed to a Constructor.
+type Params map[string]interface{}
+
+//Float returns a FloatParam.
+func (p Params) Float(name string) float64 {
+	v, _ := p[name].(float64)
+	return v
+}
+
+//Int returns an IntParam.
+func (p Params) Int(name string) int {
+	v, _ := p[name].(int)
+	return v
+}
+
+//String returns a StringParam.
+func (p Params) String(name string) string {
+	v, _ := p[name].(string)
+	return v
+}
+
+//Bool returns a BoolParam.
+func (p Params) Bool(name string) bool {
+	v, _ := p[name].(bool)
+	return v
+}
+
+//Duration returns a DurationParam.
+func (p Params) Duration(name string) time.Duration {
+	v, _ := p[name].(time.Duration)
+	return v
+}
+
+// Constructor creates a filter from its parameters.
+// It can return a ParamError to point at an invalid parameter.
+type Constructor func(p Params) (Filter, error)
+
+// Spec describes a named filter with its constructor and the schema of its parameters.
+// The order of the parameters is used for positional arguments.
+type Spec struct {
+	Name   string
+	Doc    string
+	Params []Param
+	New    Constructor
+}
+
+//Usage returns the signature and the documentation of the filter.
+func (s Spec) Usage() string {
+	var b strings.Builder
+	args := make([]string, len(s.Params))
+	for i, p := range s.Params {
+		args[i] = p.Name
+	}
+	fmt.Fprintf(&b, "%s(%s)", s.Name, strings.Join(args, ", "))
+	if s.Doc != "" {
+		fmt.Fprintf(&b, "\n    %s", s.Doc)
+	}
+	for _, p := range s.Params {
+		fmt.Fprintf(&b, "\n    %-12s %-8s", p.Name, p.Type)
+		switch {
+		case p.Required:
+			b.WriteString(" required")
+		case p.Default != nil:
+			fmt.Fprintf(&b, " default %v", p.Default)
+		}
+		if p.Doc != "" {
+			fmt.Fprintf(&b, "  %s", p.Doc)
+		}
+	}
+	return b.String()
+}
+
+// ParamError is an invalid parameter of a filter.
+type ParamError struct {
+	Filter string
+	Param  string
+	Err    error
+}
+
+func (e *ParamError) Error() string {
+	return fmt.Sprintf("filters: %s: parameter %s: %v", e.Filter, e.Param, e.Err)
+}
+
+// Registry holds named filters, so that filters can be created by
+// configuration loaders and command-line tools, and be listed and documented.
+// Names are not case-sensitive.
+type Registry struct {
+	mu    sync.RWMutex
+	specs map[string]Spec
+}
+
+//NewRegistry returns an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{specs: make(map[string]Spec)}
+}
+
+//Register adds a filter to the registry.
+//It fails if the name is taken or the spec is incomplete.
+func (r *Registry) Register(spec Spec) error {
+	if spec.Name == "" || spec.New == nil {
+		return fmt.Errorf("filters: filter needs a name and a constructor")
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key := strings.ToLower(spec.Name)
+	if _, ok := r.specs[key]; ok {
+		return fmt.Errorf("filters: filter %s is already registered", spec.Name)
+	}
+	r.specs[key] = spec
+	return nil
+}
+
+//MustRegister adds a filter to the registry and panics on error.
+func (r *Registry) MustRegister(spec Spec) {
+	if err := r.Register(spec); err != nil {
+		panic(err)
+	}
+}
+
+//Lookup returns the spec of a filter.
+func (r *Registry) Lookup(name string) (Spec, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	spec, ok := r.specs[strings.ToLower(name)]
+	return spec, ok
+}
+
+//List returns the specs of all filters sorted by name.
+func (r *Registry) List() []Spec {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	specs := make([]Spec, 0, len(r.specs))
+	for _, spec := range r.specs {
+		specs = append(specs, spec)
+	}
+	sort.Slice(specs, func(i, j int) bool { return specs[i].Name < specs[j].Name })
+	return specs
+}
+
+//New creates a filter by name.
+//The parameters are checked against the schema and converted to their types;
+//strings are parsed for the other types. Missing parameters get their default value.
+//Invalid, missing and unknown parameters are reported as ParamError.
+func (r *Registry) New(name string, params map[string]interface{}) (Filter, error) {
+	spec, ok := r.Lookup(name)
+	if !ok {
+		return nil, fmt.Errorf("filters: unknown filter %s", name)
+	}
+	values := make(Params, len(spec.Params))
+	known := make(map[string]bool, len(spec.Params))
+	for _, p := range spec.Params {
+		known[p.Name] = true
+		v, ok := params[p.Name]
+		if !ok || v == nil {
+			if p.Required {
+				return nil, &ParamError{Filter: spec.Name, Param: p.Name, Err: fmt.Errorf("missing parameter")}
+			}
+			v = p.Default
+			if v == nil {
+				continue
+			}
+		}
+		converted, err := convert(p.Type, v)
+		if err != nil {
+			return nil, &ParamError{Filter: spec.Name, Param: p.Name, Err: err}
+		}
+		values[p.Name] = converted
+	}
+	var unknown []string
+	for name := range params {
+		if !known[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return nil, &ParamError{Filter: spec.Name, Param: unknown[0], Err: fmt.Errorf("unknown parameter")}
+	}
+	return spec.New(values)
+}
+
+//convert converts a value to the type of a parameter
+func convert(t ParamType, v interface{}) (interface{}, error) {
+	s, isString := v.(string)
+	switch t {
+	case FloatParam:
+		if isString {
+			if f, err := strconv.ParseFloat(strings.TrimSpace(s), 64); err == nil {
+				return f, nil
+			}
+		} else if f, ok := toFloat(v); ok {
+			return f, nil
+		}
+		return nil, fmt.Errorf("expected a number, got %v", v)
+	case IntParam:
+		if isString {
+			if i, err := strconv.Atoi(strings.TrimSpace(s)); err == nil {
+				return i, nil
+			}
+		} else if f, ok := toFloat(v); ok && f == math.Trunc(f) {
+			return int(f), nil
+		}
+		return nil, fmt.Errorf("expected an integer, got %v", v)
+	case StringParam:
+		if isString {
+			return s, nil
+		}
+		return nil, fmt.Errorf("expected a string, got %v", v)
+	case BoolParam:
+		if b, ok := v.(bool); ok {
+			return b, nil
+		}
+		if isString {
+			if b, err := strconv.ParseBool(strings.TrimSpace(s)); err == nil {
+				return b, nil
+			}
+		}
+		return nil, fmt.Errorf("expected true or false, got %v", v)
+	case DurationParam:
+		switch d := v.(type) {
+		case time.Duration:
+			return d, nil
+		case string:
+			if parsed, err := time.ParseDuration(strings.TrimSpace(d)); err == nil {
+				return parsed, nil
+			}
+			if f, err := strconv.ParseFloat(strings.TrimSpace(d), 64); err == nil {
+				return time.Duration(f * float64(time.Second)), nil
+			}
+		default:
+			if f, ok := toFloat(v); ok {
+				return time.Duration(f * float64(time.Second)), nil
+			}
+		}
+		return nil, fmt.Errorf("expected a duration, got %v", v)
+	}
+	return v, nil
+}
+
+//toFloat converts numbers to float64
+func toFloat(v interface{}) (float64, bool) {
+	switch v.(type) {
+	case int, int16, int32, int64, float32, float64:
+		return GetFloat64(v), true
+	}
+	return 0, false
+}
+
+// DefaultRegistry holds the built-in filters and the filters added with Register.
+var DefaultRegistry = NewRegistry()
+
+//Register adds a filter to the DefaultRegistry.
+func Register(spec Spec) error {
+	return DefaultRegistry.Register(spec)
+}
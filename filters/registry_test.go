@@ -0,0 +1,116 @@
+package filters_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/konimarti/flow/filters"
+)
+
+type scale struct {
+	filters.Model
+	factor float64
+}
+
+func (s *scale) Update(v interface{}) interface{} {
+	return s.factor * v.(float64)
+}
+
+func TestRegistry(t *testing.T) {
+	r := filters.NewRegistry()
+	err := r.Register(filters.Spec{
+		Name:   "Scale",
+		Doc:    "Multiplies the values.",
+		Params: []filters.Param{{Name: "factor", Type: filters.FloatParam, Default: 1.0}},
+		New: func(p filters.Params) (filters.Filter, error) {
+			return &scale{factor: p.Float("factor")}, nil
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Register(filters.Spec{Name: "scale", New: func(filters.Params) (filters.Filter, error) { return nil, nil }}); err == nil {
+		t.Error("Expected error for duplicate name")
+	}
+
+	for _, params := range []map[string]interface{}{{"factor": 2}, {"factor": "2.0"}, {"factor": 2.0}} {
+		f, err := r.New("scale", params)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if v := f.Update(3.0); v != 6.0 {
+			t.Errorf("Got %v. Expected 6 for %v", v, params)
+		}
+	}
+	f, err := r.New("Scale", nil)
+	if err != nil || f.Update(3.0) != 3.0 {
+		t.Errorf("Got %v. Expected default factor", err)
+	}
+
+	_, err = r.New("Scale", map[string]interface{}{"factor": "two"})
+	var perr *filters.ParamError
+	if !errors.As(err, &perr) || perr.Param != "factor" {
+		t.Errorf("Got %v. Expected invalid factor", err)
+	}
+	_, err = r.New("Scale", map[string]interface{}{"fctor": 2})
+	if !errors.As(err, &perr) || perr.Param != "fctor" {
+		t.Errorf("Got %v. Expected unknown parameter", err)
+	}
+	if _, err = r.New("Unknown", nil); err == nil {
+		t.Error("Expected error for unknown filter")
+	}
+
+	if list := r.List(); len(list) != 1 || !strings.HasPrefix(list[0].Usage(), "Scale(factor)") {
+		t.Errorf("Got %v. Expected Scale in list", list)
+	}
+}
+
+func TestDefaultRegistry(t *testing.T) {
+	f, err := filters.DefaultRegistry.New("TumblingWindow", map[string]interface{}{"size": "1m", "aggregate": "p95"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w, ok := f.(*filters.TumblingWindow); !ok || w.Size != time.Minute || w.Aggregate == nil {
+		t.Errorf("Got %+v. Expected tumbling window of 1m", f)
+	}
+
+	_, err = filters.DefaultRegistry.New("MovingAverage", map[string]interface{}{"window": 0})
+	var perr *filters.ParamError
+	if !errors.As(err, &perr) || perr.Param != "window" {
+		t.Errorf("Got %v. Expected invalid window", err)
+	}
+	for _, params := range []map[string]interface{}{
+		{"size": "0s", "slide": "1s"},
+		{"size": "1m", "slide": -1},
+	} {
+		_, err = filters.DefaultRegistry.New("SlidingWindow", params)
+		if !errors.As(err, &perr) || !strings.Contains(perr.Err.Error(), "positive duration") {
+			t.Errorf("Got %v. Expected invalid duration for %v", err, params)
+		}
+	}
+	_, err = filters.DefaultRegistry.New("SessionWindow", map[string]interface{}{"gap": 0})
+	if !errors.As(err, &perr) || perr.Param != "gap" {
+		t.Errorf("Got %v. Expected invalid gap", err)
+	}
+	_, err = filters.DefaultRegistry.New("AboveFloat64", nil)
+	if !errors.As(err, &perr) || perr.Param != "value" {
+		t.Errorf("Got %v. Expected missing value", err)
+	}
+
+	for _, spec := range filters.DefaultRegistry.List() {
+		if _, err := spec.New(defaults(spec)); err != nil && spec.Name != "Mute" && !strings.Contains(spec.Name, "Window") {
+			t.Errorf("Got %v. Expected %s to be created with defaults", err, spec.Name)
+		}
+	}
+}
+
+//defaults returns the default parameters of a filter
+func defaults(spec filters.Spec) filters.Params {
+	p := filters.Params{}
+	for _, param := range spec.Params {
+		p[param.Name] = param.Default
+	}
+	return p
+}
@@ -3,14 +3,20 @@ package filters
 type switchElem struct {
 	filters []Filter
 	value   interface{}
+	err     error
 }
 
 func (s *switchElem) Check(v interface{}) bool {
+	s.err = nil
 	for _, f := range s.filters {
 		if f.Check(v) {
 			s.value = f.Update(v)
+			s.err = nil
 			return true
 		}
+		if s.err == nil {
+			s.err = errOf(f)
+		}
 	}
 	return false
 }
@@ -19,6 +25,11 @@ func (s *switchElem) Update(v interface{}) interface{} {
 	return s.value
 }
 
+//Err returns the first error of the filters if none of them was true.
+func (s *switchElem) Err() error {
+	return s.err
+}
+
 //NewSwitch accepts a list of filters and returns Switch Filter.
 //The Switch Filter evaluates all filters in sequence and
 //returns true if any of the Filters is true.
@@ -1,6 +1,7 @@
 package filters
 
-//GetFloat64 parses interface to float64, if not a number it returns 0
+//GetFloat64 parses interface to float64, if not a number it returns 0.
+//The value of an Event is parsed.
 func GetFloat64(v interface{}) float64 {
 	var ret float64
 	switch v.(type) {
@@ -16,6 +17,8 @@ func GetFloat64(v interface{}) float64 {
 		ret = float64(v.(float32))
 	case float64:
 		ret = float64(v.(float64))
+	case Event, *Event:
+		ret = GetFloat64(ValueOf(v))
 	}
 	return ret
 
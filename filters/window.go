@@ -0,0 +1,338 @@
+package filters
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/konimarti/flow/clock"
+)
+
+// Aggregate reduces the values of a window to a single value.
+type Aggregate func(values []float64) float64
+
+//Sum returns the sum of the values.
+func Sum(values []float64) float64 {
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	return sum
+}
+
+//Mean returns the arithmetic mean of the values.
+func Mean(values []float64) float64 {
+	if len(values) == 0 {
+		return math.NaN()
+	}
+	return Sum(values) / float64(len(values))
+}
+
+//Min returns the smallest value.
+func Min(values []float64) float64 {
+	min := math.Inf(1)
+	for _, v := range values {
+		min = math.Min(min, v)
+	}
+	return min
+}
+
+//Max returns the largest value.
+func Max(values []float64) float64 {
+	max := math.Inf(-1)
+	for _, v := range values {
+		max = math.Max(max, v)
+	}
+	return max
+}
+
+//Count returns the number of values.
+func Count(values []float64) float64 {
+	return float64(len(values))
+}
+
+//Percentile returns an Aggregate for the p-th percentile (0 <= p <= 100)
+//using linear interpolation between the closest ranks.
+func Percentile(p float64) Aggregate {
+	return func(values []float64) float64 {
+		if len(values) == 0 {
+			return math.NaN()
+		}
+		sorted := make([]float64, len(values))
+		copy(sorted, values)
+		sort.Float64s(sorted)
+		rank := p / 100 * float64(len(sorted)-1)
+		lower := int(math.Floor(rank))
+		upper := int(math.Ceil(rank))
+		if lower < 0 {
+			return sorted[0]
+		}
+		if upper >= len(sorted) {
+			return sorted[len(sorted)-1]
+		}
+		return sorted[lower] + (rank-float64(lower))*(sorted[upper]-sorted[lower])
+	}
+}
+
+//ParseAggregate returns the Aggregate for a name:
+//sum, mean, min, max, count or a percentile like p95.
+func ParseAggregate(name string) (Aggregate, error) {
+	switch name {
+	case "sum":
+		return Sum, nil
+	case "mean":
+		return Mean, nil
+	case "min":
+		return Min, nil
+	case "max":
+		return Max, nil
+	case "count":
+		return Count, nil
+	}
+	if strings.HasPrefix(name, "p") {
+		if p, err := strconv.ParseFloat(name[1:], 64); err == nil && p >= 0 && p <= 100 {
+			return Percentile(p), nil
+		}
+	}
+	return nil, fmt.Errorf("unknown aggregate %q", name)
+}
+
+// sample is a value with its event time
+type sample struct {
+	t time.Time
+	x float64
+}
+
+// window collects samples and aggregates them
+type window struct {
+	samples []sample
+	result  float64
+	err     error
+}
+
+//number returns the value as float64.
+//It sets the error of the window if the value is not a number.
+func (w *window) number(v interface{}) (float64, bool) {
+	w.err = nil
+	switch ValueOf(v).(type) {
+	case int, int16, int32, int64, float32, float64:
+		return GetFloat64(v), true
+	}
+	w.err = fmt.Errorf("filters: %v is not a number", ValueOf(v))
+	return 0, false
+}
+
+//Err returns the error if the last value was skipped because it is not a number.
+func (w *window) Err() error {
+	return w.err
+}
+
+//add inserts the sample in order of time
+func (w *window) add(t time.Time, x float64) {
+	i := len(w.samples)
+	for i > 0 && w.samples[i-1].t.After(t) {
+		i--
+	}
+	w.samples = append(w.samples, sample{})
+	copy(w.samples[i+1:], w.samples[i:])
+	w.samples[i] = sample{t: t, x: x}
+}
+
+//aggregate aggregates the samples in [from, to)
+func (w *window) aggregate(agg Aggregate, from, to time.Time) (float64, bool) {
+	var values []float64
+	for _, s := range w.samples {
+		if !s.t.Before(from) && s.t.Before(to) {
+			values = append(values, s.x)
+		}
+	}
+	if len(values) == 0 {
+		return 0, false
+	}
+	if agg == nil {
+		agg = Mean
+	}
+	return agg(values), true
+}
+
+//drop removes the samples before t
+func (w *window) drop(t time.Time) {
+	i := 0
+	for i < len(w.samples) && w.samples[i].t.Before(t) {
+		i++
+	}
+	w.samples = w.samples[i:]
+}
+
+//Update returns the aggregated value of the window that closed.
+func (w *window) Update(newValue interface{}) interface{} {
+	return w.result
+}
+
+// timeWindow implements windows of duration size that start every slide.
+// Windows close when the watermark passes their end.
+type timeWindow struct {
+	window
+	watermark Watermark
+	next      time.Time
+	late      uint64
+}
+
+//check adds the value and returns true if a window was closed or updated by a late value.
+//If several windows close at once, the result of the latest window is kept.
+//There are no windows if the size is not positive.
+func (w *timeWindow) check(newValue interface{}, c clock.Clock, size, slide time.Duration, lateness Lateness, agg Aggregate) bool {
+	if size <= 0 {
+		return false
+	}
+	x, ok := w.number(newValue)
+	if !ok {
+		return false
+	}
+	if slide <= 0 {
+		slide = size
+	}
+	t := timeOf(newValue, c)
+	w.watermark.Delay = lateness.Delay
+	if w.next.IsZero() {
+		w.next = t.Truncate(slide).Add(slide)
+	}
+
+	// find the windows that contain the new value
+	wm := w.watermark.Time()
+	accepted := false
+	var refire []time.Time
+	for end := t.Truncate(slide).Add(slide); !end.After(t.Add(size)); end = end.Add(slide) {
+		if !end.Before(w.next) {
+			accepted = true
+		} else if wm.Before(end.Add(lateness.Allowed)) {
+			accepted = true
+			refire = append(refire, end)
+		}
+	}
+	if !accepted {
+		w.late++
+		return false
+	}
+	w.add(t, x)
+	closed := false
+	for _, end := range refire {
+		if v, ok := w.aggregate(agg, end.Add(-size), end); ok {
+			w.result, closed = v, true
+		}
+	}
+
+	// close the windows that the watermark has passed
+	w.watermark.Observe(t)
+	wm = w.watermark.Time()
+	for !wm.Before(w.next) {
+		if v, ok := w.aggregate(agg, w.next.Add(-size), w.next); ok {
+			w.result, closed = v, true
+		}
+		w.next = w.next.Add(slide)
+		w.skip(size, slide, wm)
+	}
+	w.drop(wm.Add(-lateness.Allowed - size))
+	return closed
+}
+
+//skip moves over empty windows
+func (w *timeWindow) skip(size, slide time.Duration, wm time.Time) {
+	start := w.next.Add(-size)
+	for _, s := range w.samples {
+		if !s.t.Before(start) {
+			if next := s.t.Truncate(slide).Add(slide); next.After(w.next) {
+				w.next = next
+			}
+			return
+		}
+	}
+	w.next = wm.Truncate(slide).Add(slide)
+}
+
+//Late returns the number of values that were dropped because they arrived too late.
+func (w *timeWindow) Late() uint64 {
+	return w.late
+}
+
+// TumblingWindow implements the Filter interface.
+// It collects values in consecutive, non-overlapping windows of a fixed duration
+// and notifies the subscribers with the aggregated value when a window closes.
+// A window closes when the watermark passes its end, i.e. with the arrival of
+// the first value after its end if no Lateness is defined.
+// The event time is used for values of type Event.
+// If several windows close at once, only the result of the latest window is sent.
+// There are no windows if Size is not positive.
+// Values that are not numbers are skipped and reported by Err.
+// The default Aggregate is Mean.
+type TumblingWindow struct {
+	Size      time.Duration
+	Aggregate Aggregate
+	Lateness  Lateness
+	Clock     clock.Clock
+	timeWindow
+}
+
+//Check adds the value to the window and returns true if a window was closed.
+func (tw *TumblingWindow) Check(newValue interface{}) bool {
+	return tw.check(newValue, tw.Clock, tw.Size, tw.Size, tw.Lateness, tw.Aggregate)
+}
+
+// SlidingWindow implements the Filter interface.
+// It aggregates the values of overlapping windows of duration Size
+// that start every Slide and notifies the subscribers when a window closes.
+// Windows close like the TumblingWindow. If Slide is not positive, it is Size.
+// Values that are not numbers are skipped and reported by Err.
+// The default Aggregate is Mean.
+type SlidingWindow struct {
+	Size      time.Duration
+	Slide     time.Duration
+	Aggregate Aggregate
+	Lateness  Lateness
+	Clock     clock.Clock
+	timeWindow
+}
+
+//Check adds the value to the window and returns true if a window was closed.
+func (sw *SlidingWindow) Check(newValue interface{}) bool {
+	return sw.check(newValue, sw.Clock, sw.Size, sw.Slide, sw.Lateness, sw.Aggregate)
+}
+
+// SessionWindow implements the Filter interface.
+// A session contains all values that arrive within Gap of each other.
+// The subscribers are notified with the aggregated value of a session
+// when a value arrives after the gap.
+// The event time is used for values of type Event.
+// There is no watermark or Lateness: a value that arrives out of order
+// is added to the current session.
+// Values that are not numbers are skipped and reported by Err.
+// The default Aggregate is Mean.
+type SessionWindow struct {
+	Gap       time.Duration
+	Aggregate Aggregate
+	Clock     clock.Clock
+	last      time.Time
+	window
+}
+
+//Check adds the value to the session and returns true if the previous session was closed.
+func (sw *SessionWindow) Check(newValue interface{}) bool {
+	x, ok := sw.number(newValue)
+	if !ok {
+		return false
+	}
+	t := timeOf(newValue, sw.Clock)
+	closed := false
+	if !sw.last.IsZero() && t.Sub(sw.last) > sw.Gap {
+		sw.result, closed = sw.aggregate(sw.Aggregate, sw.samples[0].t, sw.last.Add(1))
+		sw.samples = sw.samples[:0]
+	}
+	if t.After(sw.last) {
+		sw.last = t
+	}
+	sw.add(t, x)
+	return closed
+}
@@ -0,0 +1,152 @@
+package filters_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/konimarti/flow/clock"
+	"github.com/konimarti/flow/filters"
+)
+
+func TestAggregates(t *testing.T) {
+	values := []float64{4.0, 1.0, 3.0, 2.0}
+	var config = []struct {
+		Name      string
+		Aggregate filters.Aggregate
+		Want      float64
+	}{
+		{"Sum", filters.Sum, 10.0},
+		{"Mean", filters.Mean, 2.5},
+		{"Min", filters.Min, 1.0},
+		{"Max", filters.Max, 4.0},
+		{"Count", filters.Count, 4.0},
+		{"Median", filters.Percentile(50), 2.5},
+		{"P0", filters.Percentile(0), 1.0},
+		{"P100", filters.Percentile(100), 4.0},
+	}
+	for _, cfg := range config {
+		if got := cfg.Aggregate(values); math.Abs(got-cfg.Want) > 1e-9 {
+			t.Errorf("%s: Got %v. Expected %v", cfg.Name, got, cfg.Want)
+		}
+	}
+}
+
+func TestTumblingWindow(t *testing.T) {
+	size := 100 * time.Millisecond
+	c := clock.NewManual(time.Now().Truncate(size))
+	w := &filters.TumblingWindow{Size: size, Aggregate: filters.Sum, Clock: c}
+
+	for _, v := range []float64{1.0, 2.0, 3.0} {
+		if w.Check(v) {
+			t.Error("window should still be open")
+		}
+	}
+	c.Advance(size)
+	if !w.Check(10.0) {
+		t.Fatal("window should be closed")
+	}
+	if v := w.Update(10.0); v != 6.0 {
+		t.Errorf("Got %v. Expected 6.0", v)
+	}
+}
+
+func TestSlidingWindow(t *testing.T) {
+	slide := 50 * time.Millisecond
+	c := clock.NewManual(time.Now().Truncate(slide))
+	w := &filters.SlidingWindow{Size: 2 * slide, Slide: slide, Aggregate: filters.Count, Clock: c}
+
+	w.Check(1.0)
+	c.Advance(slide)
+	if !w.Check(2.0) {
+		t.Fatal("first window should be closed")
+	}
+	if v := w.Update(2.0); v != 1.0 {
+		t.Errorf("Got %v. Expected 1.0", v)
+	}
+	c.Advance(slide)
+	if !w.Check(3.0) {
+		t.Fatal("second window should be closed")
+	}
+	if v := w.Update(3.0); v != 2.0 {
+		t.Errorf("Got %v. Expected 2.0 (windows overlap)", v)
+	}
+}
+
+func TestSlidingWindowIrregular(t *testing.T) {
+	start := time.Unix(1000, 0)
+	w := &filters.SlidingWindow{Size: 10 * time.Second, Slide: time.Second, Aggregate: filters.Sum}
+
+	// values arrive less often than the windows slide
+	for i := 0; i < 100; i++ {
+		e := filters.Event{Time: start.Add(time.Duration(i) * 5 * time.Second), Value: float64(i)}
+		closed := w.Check(e)
+		if i == 0 {
+			continue
+		}
+		if !closed {
+			t.Fatalf("%d: window should be closed", i)
+		}
+		// the latest window ends with the new value and holds the two previous values
+		want := float64(i - 1)
+		if i > 1 {
+			want += float64(i - 2)
+		}
+		if v := w.Update(e); v != want {
+			t.Fatalf("%d: got %v. Expected %v", i, v, want)
+		}
+	}
+}
+
+func TestWindowInvalidSize(t *testing.T) {
+	c := clock.NewManual(time.Now())
+	for _, w := range []filters.Filter{
+		&filters.TumblingWindow{Clock: c},
+		&filters.SlidingWindow{Size: -time.Second, Slide: time.Second, Clock: c},
+	} {
+		for i := 0; i < 3; i++ {
+			if w.Check(1.0) {
+				t.Errorf("%T: expected no windows", w)
+			}
+			c.Advance(time.Second)
+		}
+	}
+}
+
+func TestWindowSkipsNonNumbers(t *testing.T) {
+	c := clock.NewManual(time.Unix(0, 0))
+	for _, w := range []filters.Filter{
+		&filters.TumblingWindow{Size: time.Second, Aggregate: filters.Count, Clock: c},
+		&filters.SessionWindow{Gap: 500 * time.Millisecond, Aggregate: filters.Count, Clock: c},
+	} {
+		w.Check(1.0)
+		if w.Check("abc") || w.(filters.ErrReporter).Err() == nil {
+			t.Errorf("%T: expected error for a value that is not a number", w)
+		}
+		c.Advance(time.Second)
+		if !w.Check(2) || w.(filters.ErrReporter).Err() != nil {
+			t.Fatalf("%T: window should be closed", w)
+		}
+		if v := w.Update(2); v != 1.0 {
+			t.Errorf("%T: got %v. Expected 1 value in the window", w, v)
+		}
+	}
+}
+
+func TestSessionWindow(t *testing.T) {
+	c := clock.NewManual(time.Now())
+	w := &filters.SessionWindow{Gap: 50 * time.Millisecond, Aggregate: filters.Max, Clock: c}
+	for _, v := range []float64{1.0, 5.0, 3.0} {
+		if w.Check(v) {
+			t.Error("session should still be open")
+		}
+		c.Advance(10 * time.Millisecond)
+	}
+	c.Advance(100 * time.Millisecond)
+	if !w.Check(2.0) {
+		t.Fatal("session should be closed")
+	}
+	if v := w.Update(2.0); v != 5.0 {
+		t.Errorf("Got %v. Expected 5.0", v)
+	}
+}
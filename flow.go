@@ -1,8 +1,10 @@
 package flow
 
 import (
+	"context"
 	"time"
 
+	"github.com/konimarti/flow/clock"
 	"github.com/konimarti/flow/filters"
 	"github.com/konimarti/flow/observer"
 )
@@ -12,12 +14,70 @@ func New(nf filters.Filter, s Source) observer.Observer {
 	return s.Run(nf)
 }
 
+//NewWithContext returns an Observer that receives the results of the flow.
+//The flow is stopped and all subscribers are closed when the context is done.
+func NewWithContext(ctx context.Context, nf filters.Filter, s Source) observer.Observer {
+	if cs, ok := s.(ContextSource); ok {
+		return cs.RunContext(ctx, nf)
+	}
+	o := s.Run(nf)
+	go func() {
+		select {
+		case <-ctx.Done():
+			o.Close()
+		case <-o.Control().E:
+		}
+	}()
+	return o
+}
+
+//Subscribe starts the flow with a subscriber that is attached before the source starts,
+//so that it receives all values of the flow. A subscriber that is added to the
+//observer of New later misses the values that were sent before, e.g. the lines of a short file.
+func Subscribe(nf filters.Filter, s Source) (observer.Observer, observer.Subscriber) {
+	return SubscribeWithContext(context.Background(), nf, s, observer.Policy{})
+}
+
+//SubscribeWithContext starts the flow until the context is done with a subscriber that
+//is attached before the source starts. The subscriber has the given policy.
+//This works for the sources of this package and for sources that implement SubscribeSource;
+//the subscriber of other sources is attached after they have started.
+func SubscribeWithContext(ctx context.Context, nf filters.Filter, s Source, p observer.Policy) (observer.Observer, observer.Subscriber) {
+	switch s := s.(type) {
+	case SubscribeSource:
+		return s.SubscribeContext(ctx, nf, p)
+	case jobSource:
+		return s.job(nf).start(ctx, &p)
+	}
+	o := NewWithContext(ctx, nf, s)
+	return o, o.SubscribeWithPolicy(p)
+}
+
 //Source is the interface for input for the flow
 type Source interface {
 	Run(f filters.Filter) observer.Observer
 }
 
-//Func implements the Source interface and regularly calls a function
+//ContextSource is implemented by sources that can be stopped with a context
+type ContextSource interface {
+	Source
+	RunContext(ctx context.Context, f filters.Filter) observer.Observer
+}
+
+//SubscribeSource is implemented by sources that can attach a subscriber
+//before they start (see SubscribeWithContext)
+type SubscribeSource interface {
+	Source
+	SubscribeContext(ctx context.Context, f filters.Filter, p observer.Policy) (observer.Observer, observer.Subscriber)
+}
+
+//jobSource is implemented by the sources of this package
+type jobSource interface {
+	job(nf filters.Filter) job
+}
+
+//Func implements the Source interface and regularly calls a function.
+//The calls pause after the last subscriber has left until a new subscriber arrives (see observer.OnIdle).
 type Func struct {
 	Fn      func() interface{}
 	Refresh time.Duration
@@ -25,44 +85,68 @@ type Func struct {
 
 //Run calls the given function in regular intervals
 func (f *Func) Run(nf filters.Filter) observer.Observer {
-	o := observer.NewObserver()
-	c := time.Tick(f.Refresh)
-	go func() {
+	return f.RunContext(context.Background(), nf)
+}
+
+//RunContext calls the given function in regular intervals until the context is done.
+//The ticker is created by the clock of the context (see clock.NewContext).
+func (f *Func) RunContext(ctx context.Context, nf filters.Filter) observer.Observer {
+	return f.job(nf).run(ctx)
+}
+
+//job returns the flow that calls the given function in regular intervals
+func (f *Func) job(nf filters.Filter) job {
+	idle := &idler{}
+	return job{nf: nf, policy: ForwardErrors, idle: idle, produce: func(ctx context.Context, emit emitFunc) error {
+		ticker := clock.FromContext(ctx).NewTicker(f.Refresh)
+		defer ticker.Stop()
 		for {
 			select {
-			case <-c:
-				if v := f.Fn(); nf.Check(v) {
-					o.Notify(nf.Update(v))
+			case <-ticker.C():
+				if idle.skip() {
+					continue
+				}
+				if !emit(f.Fn(), nil) {
+					return nil
 				}
-			case <-o.Control().C:
-				o.Control().D <- true
-				return
+			case <-ctx.Done():
+				return ctx.Err()
 			}
 		}
-	}()
-	return o
+	}}
 }
 
-//Chan implements the Source interface and provides the input for the flow
+//Chan implements the Source interface and provides the input for the flow.
+//The flow ends when the channel is closed.
 type Chan struct {
 	Ch chan interface{}
 }
 
 //Run passed the channel data to the filters
 func (c *Chan) Run(nf filters.Filter) observer.Observer {
-	o := observer.NewObserver()
-	go func() {
+	return c.RunContext(context.Background(), nf)
+}
+
+//RunContext passes the channel data to the filters until the context is done
+func (c *Chan) RunContext(ctx context.Context, nf filters.Filter) observer.Observer {
+	return c.job(nf).run(ctx)
+}
+
+//job returns the flow that passes the channel data to the filters
+func (c *Chan) job(nf filters.Filter) job {
+	return job{nf: nf, policy: ForwardErrors, produce: func(ctx context.Context, emit emitFunc) error {
 		for {
 			select {
-			case v := <-c.Ch:
-				if nf.Check(v) {
-					o.Notify(nf.Update(v))
+			case v, ok := <-c.Ch:
+				if !ok {
+					return nil
+				}
+				if !emit(v, nil) {
+					return nil
 				}
-			case <-o.Control().C:
-				o.Control().D <- true
-				return
+			case <-ctx.Done():
+				return ctx.Err()
 			}
 		}
-	}()
-	return o
+	}}
 }
@@ -1,11 +1,13 @@
 package flow_test
 
 import (
+	"context"
 	"fmt"
 	"testing"
 	"time"
 
 	"github.com/konimarti/flow"
+	"github.com/konimarti/flow/clock"
 	"github.com/konimarti/flow/filters"
 )
 
@@ -34,13 +36,13 @@ var observers = []struct {
 	{
 		Name: "OnChange",
 		TrFunc: func(v interface{}) filters.Filter {
-			return &filters.OnChange{v}
+			return &filters.OnChange{Value: v}
 		},
 	},
 	{
 		Name: "OnValue",
 		TrFunc: func(v interface{}) filters.Filter {
-			return &filters.OnValue{v}
+			return &filters.OnValue{Value: v}
 		},
 	},
 }
@@ -72,7 +74,7 @@ func TestChannelObservers(t *testing.T) {
 			}
 
 			// create observer
-			observer := flow.New(observerCfg.TrFunc(start), &flow.Chan{ch})
+			observer := flow.New(observerCfg.TrFunc(start), &flow.Chan{Ch: ch})
 			subscriber := observer.Subscribe()
 			startC <- true
 
@@ -105,12 +107,14 @@ func TestIntervalObservers(t *testing.T) {
 			// prepare test
 			values := cfg.Values
 			var index int
+			called := make(chan bool, 1)
 			fn := func() interface{} {
 				if index > len(values) {
 					t.Error("Ran out of values.")
 				}
 				v := values[index]
 				index++
+				called <- true
 				return v
 			}
 
@@ -123,9 +127,16 @@ func TestIntervalObservers(t *testing.T) {
 				start = cfg.Want
 			}
 
-			// create observer
-			observer := flow.New(observerCfg.TrFunc(start), &flow.Func{fn, refresh})
+			// create observer with a manual clock
+			c := clock.NewManual(time.Now())
+			ctx := clock.NewContext(context.Background(), c)
+			observer := flow.NewWithContext(ctx, observerCfg.TrFunc(start), &flow.Func{Fn: fn, Refresh: refresh})
 			subscriber := observer.Subscribe()
+			c.BlockUntil(1)
+			for range values {
+				c.Advance(refresh)
+				<-called
+			}
 			// run test
 			select {
 			case <-time.After(1 * time.Second):
@@ -144,3 +155,181 @@ func TestIntervalObservers(t *testing.T) {
 		}
 	}
 }
+
+func TestNewWithContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	sources := []flow.Source{
+		&flow.Chan{Ch: make(chan interface{})},
+		&flow.Func{Fn: func() interface{} { return 1.0 }, Refresh: 10 * time.Millisecond},
+	}
+
+	for _, source := range sources {
+		observer := flow.NewWithContext(ctx, &filters.None{}, source)
+		subscriber := observer.Subscribe()
+		defer observer.Close()
+
+		select {
+		case <-subscriber.Done():
+			t.Fatal("subscriber should not be done before cancel")
+		default:
+		}
+	}
+
+	cancel()
+
+	for _, source := range sources {
+		observer := flow.NewWithContext(ctx, &filters.None{}, source)
+		subscriber := observer.Subscribe()
+		select {
+		case <-time.After(1 * time.Second):
+			t.Fatal("timed out waiting for the flow to stop")
+		case <-subscriber.Done():
+		}
+
+		// Close returns although the run loop is gone
+		ch := make(chan bool, 1)
+		go func() {
+			observer.Close()
+			ch <- true
+		}()
+		select {
+		case <-time.After(1 * time.Second):
+			t.Fatal("Close blocked after the context was cancelled")
+		case <-ch:
+		}
+	}
+}
+
+func TestCloseSubscribers(t *testing.T) {
+	observer := flow.New(&filters.None{}, &flow.Func{Fn: func() interface{} { return 1.0 }, Refresh: time.Millisecond})
+	subscriber := observer.Subscribe()
+	observer.Close()
+
+	for {
+		select {
+		case <-time.After(1 * time.Second):
+			t.Fatal("timed out waiting for subscriber to close")
+		case <-subscriber.C():
+			subscriber.Value()
+		case <-subscriber.Done():
+			return
+		}
+	}
+}
+
+func TestChanEndOfStream(t *testing.T) {
+	ch := make(chan interface{})
+	observer := flow.New(&filters.None{}, &flow.Chan{Ch: ch})
+	defer observer.Close()
+	subscriber := observer.Subscribe()
+
+	go func() {
+		for i := 0; i < 3; i++ {
+			ch <- i
+		}
+		close(ch)
+	}()
+
+	var received []interface{}
+	for {
+		select {
+		case <-time.After(1 * time.Second):
+			t.Fatal("timed out waiting for end of stream")
+		case <-subscriber.C():
+			received = append(received, subscriber.Value())
+			continue
+		case <-subscriber.Done():
+		}
+		break
+	}
+
+	if len(received) != 3 {
+		t.Errorf("Got %v. Expected 3 values", received)
+	}
+	if err := subscriber.Err(); err != nil {
+		t.Errorf("Got error %v. Expected nil", err)
+	}
+}
+
+func TestContextError(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	observer := flow.NewWithContext(ctx, &filters.None{}, &flow.Chan{Ch: make(chan interface{})})
+	subscriber := observer.Subscribe()
+	cancel()
+
+	select {
+	case <-time.After(1 * time.Second):
+		t.Fatal("timed out")
+	case <-subscriber.Done():
+		if subscriber.Err() != context.Canceled {
+			t.Errorf("Got error %v. Expected %v", subscriber.Err(), context.Canceled)
+		}
+	}
+}
+
+func TestEventTimestamp(t *testing.T) {
+	ch := make(chan interface{})
+	observer := flow.New(&filters.None{}, &flow.Chan{Ch: ch})
+	defer observer.Close()
+	subscriber := observer.Subscribe()
+
+	recorded := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	go func() {
+		ch <- filters.Event{Key: "a", Value: 1.0}
+		ch <- filters.Event{Time: recorded, Key: "b", Value: 2.0}
+	}()
+
+	for i := 0; i < 2; i++ {
+		select {
+		case <-time.After(1 * time.Second):
+			t.Fatal("timed out")
+		case <-subscriber.C():
+			e := subscriber.Value().(filters.Event)
+			switch e.Key {
+			case "a":
+				if e.Time.IsZero() {
+					t.Error("event without time should be stamped by the source")
+				}
+			case "b":
+				if !e.Time.Equal(recorded) {
+					t.Error("event time should not be changed")
+				}
+			}
+		}
+	}
+}
+
+//stepClock is a clock with a ticker that delivers a tick only when the flow receives it,
+//so that the previous tick has been handled when the next one is sent
+type stepClock struct {
+	clock.Clock
+	tick chan time.Time
+}
+
+func (s *stepClock) NewTicker(d time.Duration) clock.Ticker { return s }
+func (s *stepClock) C() <-chan time.Time                    { return s.tick }
+func (s *stepClock) Stop()                                  {}
+
+func TestFuncPausesWhenIdle(t *testing.T) {
+	c := &stepClock{Clock: clock.Real, tick: make(chan time.Time)}
+	ctx := clock.NewContext(context.Background(), c)
+	n := 0
+	observer := flow.NewWithContext(ctx, &filters.None{}, &flow.Func{Fn: func() interface{} {
+		n++
+		return n
+	}, Refresh: time.Second})
+	defer observer.Close()
+
+	subscriber := observer.Subscribe()
+	c.tick <- time.Now()
+	expect(t, subscriber, 1)
+
+	// the function is not called while there are no subscribers
+	subscriber.Close()
+	c.tick <- time.Now()
+	c.tick <- time.Now()
+	subscriber = observer.Subscribe()
+	c.tick <- time.Now()
+	expect(t, subscriber, 2)
+}
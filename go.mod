@@ -1,9 +1,13 @@
 module github.com/konimarti/flow
 
-go 1.12
+go 1.18
 
 require (
 	github.com/codahale/hdrhistogram v0.0.0-20161010025455-3a0bb77429bd
-	github.com/drewlanenga/govector v0.0.0-20160727150047-f69e9f02317e // indirect
 	github.com/lytics/anomalyzer v0.0.0-20151102000650-13cee1061701
+	gopkg.in/yaml.v3 v3.0.1
+)
+
+require (
+	github.com/drewlanenga/govector v0.0.0-20160727150047-f69e9f02317e // indirect
 )
@@ -0,0 +1,184 @@
+package flow
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/konimarti/flow/clock"
+	"github.com/konimarti/flow/filters"
+	"github.com/konimarti/flow/observer"
+)
+
+//maxResponseSize is the maximal size of a response body
+const maxResponseSize = 16 * 1024 * 1024
+
+//HTTP implements the Source interface and polls an HTTP endpoint in regular intervals.
+//The value is extracted from the response body with the JSONPath or the Regexp
+//and passed to the filters. Failed requests, responses with a status other than 2xx
+//and extraction errors are handled according to the Policy.
+//Polling pauses after the last subscriber has left until a new subscriber arrives (see observer.OnIdle).
+type HTTP struct {
+	URL string
+	//Method is the HTTP method (default GET)
+	Method string
+	Header http.Header
+	//Refresh is the polling interval. The flow ends with an error if it is not positive.
+	Refresh time.Duration
+	//Timeout is the timeout of a request (default Refresh)
+	Timeout time.Duration
+	//Client is the HTTP client (default http.DefaultClient)
+	Client *http.Client
+	//JSONPath selects a value of a JSON response, e.g. "data.sensors[0].value".
+	//The value is passed to the filters as is (numbers are float64).
+	JSONPath string
+	//Regexp extracts the first submatch (or the match if there is no group) from the body
+	Regexp *regexp.Regexp
+	//Decoder decodes the body or the match of the Regexp (default FloatDecoder).
+	//It is not used with a JSONPath.
+	Decoder Decoder
+	Policy  ErrorPolicy
+}
+
+//Run polls the endpoint in regular intervals
+func (h *HTTP) Run(nf filters.Filter) observer.Observer {
+	return h.RunContext(context.Background(), nf)
+}
+
+//RunContext polls the endpoint in regular intervals until the context is done
+func (h *HTTP) RunContext(ctx context.Context, nf filters.Filter) observer.Observer {
+	return h.job(nf).run(ctx)
+}
+
+//job returns the flow that polls the endpoint in regular intervals
+func (h *HTTP) job(nf filters.Filter) job {
+	idle := &idler{}
+	return job{nf: nf, policy: h.Policy, idle: idle, produce: func(ctx context.Context, emit emitFunc) error {
+		if h.Refresh <= 0 {
+			return fmt.Errorf("flow: http: refresh must be positive, got %v", h.Refresh)
+		}
+		ticker := clock.FromContext(ctx).NewTicker(h.Refresh)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C():
+				if idle.skip() {
+					continue
+				}
+				if !emit(h.poll(ctx)) {
+					return nil
+				}
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+	}}
+}
+
+//poll requests the endpoint and extracts the value
+func (h *HTTP) poll(ctx context.Context) (interface{}, error) {
+	timeout := h.Timeout
+	if timeout <= 0 {
+		timeout = h.Refresh
+	}
+	body, err := fetch(ctx, h.Client, h.Method, h.URL, h.Header, timeout)
+	if err != nil {
+		return nil, err
+	}
+	return h.extract(body)
+}
+
+//fetch sends a request and returns the body of a successful response
+func fetch(ctx context.Context, client *http.Client, method, url string, header http.Header, timeout time.Duration) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	if method == "" {
+		method = http.MethodGet
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("flow: %s %s: %s", method, url, resp.Status)
+	}
+	return body, nil
+}
+
+//extract returns the value of the response body
+func (h *HTTP) extract(body []byte) (interface{}, error) {
+	if h.JSONPath != "" {
+		var v interface{}
+		d := json.NewDecoder(bytes.NewReader(body))
+		d.UseNumber()
+		if err := d.Decode(&v); err != nil {
+			return nil, err
+		}
+		return lookup(normalize(v), h.JSONPath)
+	}
+	if h.Regexp != nil {
+		match := h.Regexp.FindSubmatch(body)
+		if match == nil {
+			return nil, fmt.Errorf("flow: %s does not match the response of %s", h.Regexp, h.URL)
+		}
+		body = match[0]
+		if len(match) > 1 {
+			body = match[1]
+		}
+	}
+	if h.Decoder == nil {
+		return FloatDecoder.Decode(body)
+	}
+	return h.Decoder.Decode(body)
+}
+
+//lookup selects a value of a decoded JSON document by a path
+//of object keys and array indices, e.g. "items[0].value" or "items.0.value"
+func lookup(v interface{}, path string) (interface{}, error) {
+	keys := strings.Split(strings.NewReplacer("[", ".", "]", "").Replace(path), ".")
+	for _, key := range keys {
+		if key == "" {
+			continue
+		}
+		switch t := v.(type) {
+		case map[string]interface{}:
+			e, ok := t[key]
+			if !ok {
+				return nil, fmt.Errorf("flow: key %q not found in %s", key, path)
+			}
+			v = e
+		case []interface{}:
+			i, err := strconv.Atoi(key)
+			if err != nil || i < 0 || i >= len(t) {
+				return nil, fmt.Errorf("flow: invalid index %q in %s", key, path)
+			}
+			v = t[i]
+		default:
+			return nil, fmt.Errorf("flow: cannot select %q in %s", key, path)
+		}
+	}
+	return v, nil
+}
@@ -0,0 +1,106 @@
+package flow_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/konimarti/flow"
+	"github.com/konimarti/flow/clock"
+	"github.com/konimarti/flow/filters"
+)
+
+//pollHTTP runs the source with a manual clock and returns the first value or error
+func pollHTTP(t *testing.T, source *flow.HTTP) (interface{}, error) {
+	c := clock.NewManual(time.Now())
+	ctx := clock.NewContext(context.Background(), c)
+	if source.Refresh == 0 {
+		source.Refresh = time.Second
+	}
+	observer := flow.NewWithContext(ctx, &filters.None{}, source)
+	defer observer.Close()
+	subscriber := observer.Subscribe()
+
+	c.BlockUntil(1)
+	c.Advance(source.Refresh)
+	select {
+	case <-subscriber.C():
+		return subscriber.Value(), nil
+	case err := <-observer.Errors():
+		return nil, err
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for poll")
+	}
+	return nil, nil
+}
+
+func TestHTTPJSONPath(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`{"sensors":[{"value":1.5},{"value":2.5}]}`))
+	}))
+	defer server.Close()
+
+	v, err := pollHTTP(t, &flow.HTTP{
+		URL:      server.URL,
+		Header:   http.Header{"Authorization": {"Bearer token"}},
+		JSONPath: "sensors[1].value",
+	})
+	if err != nil || v != 2.5 {
+		t.Errorf("Got %v and %v. Expected 2.5", v, err)
+	}
+
+	_, err = pollHTTP(t, &flow.HTTP{URL: server.URL, JSONPath: "sensors[1].value"})
+	if err == nil {
+		t.Error("Expected error for status 401")
+	}
+}
+
+func TestHTTPRegexp(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("temperature=21.5C humidity=40%"))
+	}))
+	defer server.Close()
+
+	v, err := pollHTTP(t, &flow.HTTP{URL: server.URL, Regexp: regexp.MustCompile(`humidity=([0-9.]+)`)})
+	if err != nil || v != 40.0 {
+		t.Errorf("Got %v and %v. Expected 40", v, err)
+	}
+
+	_, err = pollHTTP(t, &flow.HTTP{URL: server.URL, Regexp: regexp.MustCompile(`pressure=([0-9.]+)`)})
+	if err == nil {
+		t.Error("Expected error if regexp does not match")
+	}
+}
+
+func TestHTTPTimeout(t *testing.T) {
+	block := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-block:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(block)
+
+	_, err := pollHTTP(t, &flow.HTTP{URL: server.URL, Timeout: 10 * time.Millisecond})
+	if err == nil {
+		t.Error("Expected timeout error")
+	}
+}
+
+func TestHTTPInvalidRefresh(t *testing.T) {
+	_, subscriber := flow.Subscribe(&filters.None{}, &flow.HTTP{URL: "http://localhost/"})
+	collect(t, subscriber)
+	err := subscriber.Err()
+	if _, ok := err.(*flow.PanicError); ok || err == nil || err.Error() != "flow: http: refresh must be positive, got 0s" {
+		t.Errorf("Got %v. Expected invalid refresh", err)
+	}
+}
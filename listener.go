@@ -0,0 +1,258 @@
+package flow
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"sync"
+
+	"github.com/konimarti/flow/filters"
+	"github.com/konimarti/flow/observer"
+)
+
+//maxDatagramSize is the size of the receive buffer for datagrams
+const maxDatagramSize = 64 * 1024
+
+//Listener implements the Source interface and accepts connections on a stream socket,
+//e.g. TCP ("tcp") or Unix domain sockets ("unix"). Every line that is received is decoded
+//by the Decoder (default StringDecoder) and passed to the filters as a filters.Event
+//with the remote address in Meta["remote"].
+//MaxConns limits the number of concurrent connections (0 is unlimited); further
+//connections are closed right away. Decoding errors are handled according to the Policy.
+type Listener struct {
+	//Network is the network of the socket (default "tcp")
+	Network string
+	Address string
+	//Listener is used instead of Network and Address if set. It is closed when the flow ends.
+	Listener net.Listener
+	Decoder  Decoder
+	MaxConns int
+	Policy   ErrorPolicy
+}
+
+//Run accepts connections and passes the decoded lines to the filters
+func (l *Listener) Run(nf filters.Filter) observer.Observer {
+	return l.RunContext(context.Background(), nf)
+}
+
+//RunContext accepts connections and passes the decoded lines to the filters until the context is done
+func (l *Listener) RunContext(ctx context.Context, nf filters.Filter) observer.Observer {
+	return l.job(nf).run(ctx)
+}
+
+//job returns the flow that accepts connections and passes the decoded lines to the filters
+func (l *Listener) job(nf filters.Filter) job {
+	decoder := decoderOr(l.Decoder)
+	return job{nf: nf, policy: l.Policy, produce: func(ctx context.Context, emit emitFunc) error {
+		ln := l.Listener
+		if ln == nil {
+			network := l.Network
+			if network == "" {
+				network = "tcp"
+			}
+			var err error
+			if ln, err = net.Listen(network, l.Address); err != nil {
+				return err
+			}
+		}
+
+		ctx, cancel := context.WithCancel(ctx)
+		records := make(chan record)
+		errc := make(chan error, 1)
+		conns := &connSet{conns: make(map[net.Conn]struct{})}
+		var wg sync.WaitGroup
+		defer func() {
+			cancel()
+			ln.Close()
+			conns.close()
+			wg.Wait()
+		}()
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				conn, err := ln.Accept()
+				if err != nil {
+					errc <- err
+					return
+				}
+				if !conns.add(conn, l.MaxConns) {
+					conn.Close()
+					continue
+				}
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					defer conns.remove(conn)
+					remote := addrString(conn.RemoteAddr())
+					scan(ctx, conn, func(line []byte) bool {
+						return send(ctx, records, record{line, remote})
+					})
+				}()
+			}
+		}()
+
+		return receive(ctx, records, errc, decoder, emit)
+	}}
+}
+
+//PacketListener implements the Source interface and receives datagrams on a packet socket,
+//e.g. UDP ("udp") or Unix datagram sockets ("unixgram"). Every line of a datagram is decoded
+//by the Decoder (default StringDecoder) and passed to the filters as a filters.Event
+//with the address of the sender in Meta["remote"].
+//Decoding errors are handled according to the Policy.
+type PacketListener struct {
+	//Network is the network of the socket (default "udp")
+	Network string
+	Address string
+	//Conn is used instead of Network and Address if set. It is closed when the flow ends.
+	Conn    net.PacketConn
+	Decoder Decoder
+	Policy  ErrorPolicy
+}
+
+//Run receives datagrams and passes the decoded lines to the filters
+func (p *PacketListener) Run(nf filters.Filter) observer.Observer {
+	return p.RunContext(context.Background(), nf)
+}
+
+//RunContext receives datagrams and passes the decoded lines to the filters until the context is done
+func (p *PacketListener) RunContext(ctx context.Context, nf filters.Filter) observer.Observer {
+	return p.job(nf).run(ctx)
+}
+
+//job returns the flow that receives datagrams and passes the decoded lines to the filters
+func (p *PacketListener) job(nf filters.Filter) job {
+	decoder := decoderOr(p.Decoder)
+	return job{nf: nf, policy: p.Policy, produce: func(ctx context.Context, emit emitFunc) error {
+		conn := p.Conn
+		if conn == nil {
+			network := p.Network
+			if network == "" {
+				network = "udp"
+			}
+			var err error
+			if conn, err = net.ListenPacket(network, p.Address); err != nil {
+				return err
+			}
+		}
+
+		ctx, cancel := context.WithCancel(ctx)
+		records := make(chan record)
+		errc := make(chan error, 1)
+		var wg sync.WaitGroup
+		defer func() {
+			cancel()
+			conn.Close()
+			wg.Wait()
+		}()
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			buf := make([]byte, maxDatagramSize)
+			for {
+				n, addr, err := conn.ReadFrom(buf)
+				if err != nil {
+					errc <- err
+					return
+				}
+				remote := addrString(addr)
+				for _, line := range bytes.Split(buf[:n], []byte("\n")) {
+					line = bytes.TrimSuffix(line, []byte("\r"))
+					if len(line) == 0 {
+						continue
+					}
+					if !send(ctx, records, record{append([]byte(nil), line...), remote}) {
+						return
+					}
+				}
+			}
+		}()
+
+		return receive(ctx, records, errc, decoder, emit)
+	}}
+}
+
+//record is a line received from a remote address
+type record struct {
+	line   []byte
+	remote string
+}
+
+//send passes the record to the flow and returns false if the context is done
+func send(ctx context.Context, records chan<- record, r record) bool {
+	select {
+	case records <- r:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+//receive decodes and emits the records of the connections
+//until the socket fails or the context is done
+func receive(ctx context.Context, records <-chan record, errc <-chan error, decoder Decoder, emit emitFunc) error {
+	for {
+		select {
+		case r := <-records:
+			v, err := decoder.Decode(r.line)
+			if err == nil {
+				v = filters.Event{Value: v, Meta: map[string]string{"remote": r.remote}}
+			}
+			if !emit(v, err) {
+				return nil
+			}
+		case err := <-errc:
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return err
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func addrString(addr net.Addr) string {
+	if addr == nil {
+		return ""
+	}
+	return addr.String()
+}
+
+//connSet keeps track of the open connections of a listener
+type connSet struct {
+	sync.Mutex
+	conns  map[net.Conn]struct{}
+	closed bool
+}
+
+//add adds the connection if the set is open and has less than max connections
+func (s *connSet) add(conn net.Conn, max int) bool {
+	s.Lock()
+	defer s.Unlock()
+	if s.closed || (max > 0 && len(s.conns) >= max) {
+		return false
+	}
+	s.conns[conn] = struct{}{}
+	return true
+}
+
+func (s *connSet) remove(conn net.Conn) {
+	s.Lock()
+	defer s.Unlock()
+	delete(s.conns, conn)
+	conn.Close()
+}
+
+//close closes all connections
+func (s *connSet) close() {
+	s.Lock()
+	defer s.Unlock()
+	s.closed = true
+	for conn := range s.conns {
+		conn.Close()
+	}
+}
@@ -0,0 +1,144 @@
+package flow_test
+
+import (
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/konimarti/flow"
+	"github.com/konimarti/flow/filters"
+	"github.com/konimarti/flow/observer"
+)
+
+//expectEvent receives the next value and checks that it is an event from remote
+func expectEvent(t *testing.T, subscriber observer.Subscriber, want interface{}, remote string) {
+	select {
+	case <-time.After(2 * time.Second):
+		t.Fatalf("timed out waiting for %v", want)
+	case <-subscriber.C():
+		e, ok := subscriber.Value().(filters.Event)
+		if !ok || e.Value != want || e.Meta["remote"] != remote {
+			t.Fatalf("Got %+v. Expected %v from %s", subscriber.Value(), want, remote)
+		}
+	}
+}
+
+func TestListenerTCP(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	observer := flow.New(&filters.None{}, &flow.Listener{Listener: ln, Decoder: flow.FloatDecoder, MaxConns: 1})
+	defer observer.Close()
+	subscriber := observer.Subscribe()
+
+	first, err := net.Dial("tcp", ln.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	first.Write([]byte("1\n2\n"))
+	expectEvent(t, subscriber, 1.0, first.LocalAddr().String())
+	expectEvent(t, subscriber, 2.0, first.LocalAddr().String())
+
+	// the second connection exceeds the limit
+	second, err := net.Dial("tcp", ln.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	second.SetReadDeadline(time.Now().Add(2 * time.Second))
+	if _, err := second.Read(make([]byte, 1)); err == nil || isTimeout(err) {
+		t.Errorf("Got %v. Expected connection to be closed", err)
+	}
+	second.Close()
+
+	// a new connection is accepted after the first has been closed
+	first.Close()
+	var third net.Conn
+	for i := 0; ; i++ {
+		if i == 100 {
+			t.Fatal("connection was not accepted")
+		}
+		third, err = net.Dial("tcp", ln.Addr().String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		third.SetReadDeadline(time.Now().Add(20 * time.Millisecond))
+		if _, err := third.Read(make([]byte, 1)); isTimeout(err) {
+			break
+		}
+		third.Close()
+	}
+	defer third.Close()
+	third.Write([]byte("3\n"))
+	expectEvent(t, subscriber, 3.0, third.LocalAddr().String())
+}
+
+func isTimeout(err error) bool {
+	e, ok := err.(net.Error)
+	return ok && e.Timeout()
+}
+
+func TestListenerUnix(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "flow.sock")
+	observer := flow.New(&filters.None{}, &flow.Listener{Network: "unix", Address: path})
+	defer observer.Close()
+	subscriber := observer.Subscribe()
+
+	var conn net.Conn
+	var err error
+	for i := 0; i < 100; i++ {
+		if conn, err = net.Dial("unix", path); err == nil {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	conn.Write([]byte("hello\n"))
+	expectEvent(t, subscriber, "hello", addrOf(conn.LocalAddr()))
+}
+
+func addrOf(addr net.Addr) string {
+	if addr == nil {
+		return ""
+	}
+	return addr.String()
+}
+
+func TestListenerError(t *testing.T) {
+	observer := flow.New(&filters.None{}, &flow.Listener{Network: "invalid"})
+	subscriber := observer.Subscribe()
+	collect(t, subscriber)
+	if subscriber.Err() == nil {
+		t.Error("Expected error for invalid network")
+	}
+}
+
+func TestPacketListener(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	observer := flow.New(&filters.None{}, &flow.PacketListener{Conn: conn, Decoder: flow.FloatDecoder, Policy: flow.SkipErrors})
+	defer observer.Close()
+	subscriber := observer.Subscribe()
+
+	client, err := net.Dial("udp", conn.LocalAddr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	client.Write([]byte("1\nabc\n2"))
+	expectEvent(t, subscriber, 1.0, client.LocalAddr().String())
+	expectEvent(t, subscriber, 2.0, client.LocalAddr().String())
+
+	observer.Close()
+	select {
+	case <-subscriber.Done():
+	case <-time.After(time.Second):
+		t.Fatal("flow did not end")
+	}
+}
@@ -0,0 +1,79 @@
+package flow
+
+import (
+	"context"
+
+	"github.com/konimarti/flow/filters"
+	"github.com/konimarti/flow/observer"
+)
+
+//FromObserver returns a Source that passes the values of an observer to the filters.
+//It makes it possible to chain flows: every flow that runs the source subscribes
+//to the observer, so one upstream flow can feed several downstream flows.
+//A downstream flow ends when the upstream stream ends, with the same error.
+//Closing a downstream flow unsubscribes it without closing the observer.
+//The errors of the observer are not forwarded.
+func FromObserver(o observer.Observer) Source {
+	return &observerSource{o: o}
+}
+
+type observerSource struct {
+	o observer.Observer
+}
+
+//Run subscribes to the observer and passes its values to the filters
+func (s *observerSource) Run(nf filters.Filter) observer.Observer {
+	return s.RunContext(context.Background(), nf)
+}
+
+//RunContext subscribes to the observer and passes its values to the filters until the context is done
+func (s *observerSource) RunContext(ctx context.Context, nf filters.Filter) observer.Observer {
+	return s.job(nf).run(ctx)
+}
+
+//job subscribes to the observer and returns the flow that passes its values to the filters
+func (s *observerSource) job(nf filters.Filter) job {
+	return (&subscriberSource{sub: s.o.Subscribe()}).job(nf)
+}
+
+//FromSubscriber returns a Source that passes the values of a subscriber to the filters.
+//Use it to chain flows with a subscription policy, e.g. o.SubscribeWithPolicy(p).
+//The flow ends when the stream of the subscriber ends, with the same error.
+//The subscriber is closed when the flow ends, so the source can only be run once.
+func FromSubscriber(sub observer.Subscriber) Source {
+	return &subscriberSource{sub: sub}
+}
+
+type subscriberSource struct {
+	sub observer.Subscriber
+}
+
+//Run passes the values of the subscriber to the filters
+func (s *subscriberSource) Run(nf filters.Filter) observer.Observer {
+	return s.RunContext(context.Background(), nf)
+}
+
+//RunContext passes the values of the subscriber to the filters until the context is done
+func (s *subscriberSource) RunContext(ctx context.Context, nf filters.Filter) observer.Observer {
+	return s.job(nf).run(ctx)
+}
+
+//job returns the flow that passes the values of the subscriber to the filters
+func (s *subscriberSource) job(nf filters.Filter) job {
+	sub := s.sub
+	return job{nf: nf, policy: ForwardErrors, produce: func(ctx context.Context, emit emitFunc) error {
+		defer sub.Close()
+		for {
+			select {
+			case <-sub.C():
+				if !emit(sub.Value(), nil) {
+					return nil
+				}
+			case <-sub.Done():
+				return sub.Err()
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+	}}
+}
@@ -0,0 +1,53 @@
+package flow_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/konimarti/flow"
+	"github.com/konimarti/flow/filters"
+	"github.com/konimarti/flow/observer"
+)
+
+func TestFromObserver(t *testing.T) {
+	ch := make(chan interface{})
+	upstream := flow.New(&filters.None{}, &flow.Chan{Ch: ch})
+	defer upstream.Close()
+
+	above := flow.New(&filters.AboveFloat64{Value: 5.0}, flow.FromObserver(upstream))
+	below := flow.New(&filters.BelowFloat64{Value: 5.0}, flow.FromObserver(upstream))
+	aboveSub, belowSub := above.Subscribe(), below.Subscribe()
+
+	for _, v := range []float64{1, 7, 3, 9} {
+		ch <- v
+	}
+	expect(t, aboveSub, 7.0, 9.0)
+	expect(t, belowSub, 1.0, 3.0)
+
+	// closing a downstream flow does not affect the upstream flow
+	below.Close()
+	if upstream.Subscribers() != 1 {
+		t.Errorf("Got %d subscribers. Expected downstream flow to unsubscribe", upstream.Subscribers())
+	}
+
+	close(ch)
+	collect(t, aboveSub)
+	if aboveSub.Err() != nil {
+		t.Errorf("Got %v. Expected end of stream", aboveSub.Err())
+	}
+}
+
+func TestFromSubscriber(t *testing.T) {
+	o := observer.NewObserver()
+	downstream := flow.New(&filters.None{}, flow.FromSubscriber(o.SubscribeWithPolicy(observer.Policy{MaxLag: 1})))
+	subscriber := downstream.Subscribe()
+
+	o.Notify(1)
+	expect(t, subscriber, 1)
+	o.Finish(errors.New("upstream failed"))
+
+	collect(t, subscriber)
+	if err := subscriber.Err(); err == nil || err.Error() != "upstream failed" {
+		t.Errorf("Got %v. Expected error of upstream", err)
+	}
+}
@@ -2,21 +2,27 @@ package observer
 
 //control struct is used to shut down an observer gracefully.
 //It implements the io.Closer interface.
+//The E channel is closed when the run loop has exited on its own.
 type control struct {
 	C chan bool
 	D chan bool
+	E chan struct{}
 }
 
 //Close function closes the channel and waits for the done channel.
+//It returns immediately if the run loop has already exited.
 func (c *control) Close() {
 	if c.C != nil && c.D != nil {
-		c.C <- true
-		<-c.D
+		select {
+		case c.C <- true:
+			<-c.D
+		case <-c.E:
+		}
 	}
 }
 
 //NewControl creates a new control structure for graceful closing
 //of the observer run loop
 func NewControl() control {
-	return control{C: make(chan bool), D: make(chan bool)}
+	return control{C: make(chan bool), D: make(chan bool), E: make(chan struct{})}
 }
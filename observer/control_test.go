@@ -37,3 +37,20 @@ func TestControl(t *testing.T) {
 		}
 	}
 }
+
+func TestControlExited(t *testing.T) {
+	control := observer.NewControl()
+	close(control.E)
+
+	ch := make(chan bool, 1)
+	go func() {
+		control.Close()
+		ch <- true
+	}()
+
+	select {
+	case <-time.After(2 * time.Second):
+		t.Error("Close should not block when the run loop has exited.")
+	case <-ch:
+	}
+}
@@ -1,6 +1,7 @@
 package observer
 
 import (
+	"runtime"
 	"sync"
 )
 
@@ -8,16 +9,54 @@ import (
 type Observer interface {
 	Notify(interface{})
 	Subscribe() Subscriber
+	//SubscribeWithPolicy returns a new subscriber that
+	//lags behind by at most the number of values defined by the policy.
+	SubscribeWithPolicy(Policy) Subscriber
+	//SubscribeWithReplay returns a new subscriber that receives up to n
+	//of the most recent values before the new ones.
+	//Only the values that are kept for replay are available, i.e. at most one value
+	//unless Replay was called with a larger number before the values were sent.
+	//A negative n is treated as zero.
+	SubscribeWithReplay(n int) Subscriber
+	//Replay sets the number of recent values that are kept for replay (default 1).
+	Replay(n int)
+	//Latest returns the most recent value and false if there is none.
+	Latest() (interface{}, bool)
+	//Unsubscribe detaches the subscriber from the observer.
+	Unsubscribe(Subscriber)
+	//Subscribers returns the number of active subscribers.
+	//A subscriber is active until it is closed. A subscriber without
+	//a MaxLag also leaves when it is garbage collected.
+	Subscribers() int
+	//OnIdle registers a function that is called when the last subscriber leaves.
+	OnIdle(func())
 	Control() control
 	Close()
+	//Finish ends the stream. All subscribers are closed and
+	//subsequent notifications are ignored.
+	Finish(error)
+	//NotifyError forwards an error to the error channel.
+	NotifyError(error)
+	//Errors returns the channel with the forwarded errors.
+	//It is closed when the stream ends.
+	Errors() <-chan error
 }
 
+//errorBuffer is the number of forwarded errors that are buffered.
+//Errors are dropped if the buffer is full.
+const errorBuffer = 16
+
 //NewObserver returns an implementation of the observer interface
 func NewObserver() Observer {
 	o := observerI{
 		control: NewControl(),
 		state:   NewState(),
+		errors:  make(chan error, errorBuffer),
+		subs:    make(map[*subscriber]struct{}),
+		replay:  1,
 	}
+	o.tail = o.state
+	o.cond = sync.NewCond(&o.RWMutex)
 	return &o
 }
 
@@ -26,27 +65,212 @@ type observerI struct {
 	sync.RWMutex //embedded
 	control      //embedded
 	state        *state
+	tail         *state
+	latest       *state
+	replay       int
+	errors       chan error
+	subs         map[*subscriber]struct{}
+	cond         *sync.Cond
+	count        int
+	onIdle       []func()
+	finished     bool
+	closing      bool
+	controlled   bool
 }
 
 //Notify sends out the current value in the observer channel
 func (o *observerI) Notify(value interface{}) {
 	o.Lock()
 	defer o.Unlock()
+	for !o.finished && !o.closing && o.lagging() {
+		o.cond.Wait()
+	}
+	if o.finished {
+		return
+	}
 	o.state.Value = value
 	next := NewState()
+	next.Seq = o.state.Seq + 1
 	o.state.Next = next
 	close(o.state.C)
+	o.latest = o.state
 	o.state = o.state.Next
+	o.trim()
+	for s := range o.subs {
+		s.enforce(o.state)
+	}
+}
+
+//lagging returns true if a subscriber blocks the publisher
+func (o *observerI) lagging() bool {
+	for s := range o.subs {
+		if s.lagging(o.state) {
+			return true
+		}
+	}
+	return false
+}
+
+//Finish marks the end of the stream and closes all subscribers
+func (o *observerI) Finish(err error) {
+	o.Lock()
+	defer o.Unlock()
+	if o.finished {
+		return
+	}
+	o.finished = true
+	o.state.Err = err
+	close(o.state.E)
+	close(o.control.E)
+	close(o.errors)
+	o.cond.Broadcast()
+}
+
+//Close stops the run loop and closes all subscribers.
+//An observer without a run loop is closed immediately.
+func (o *observerI) Close() {
+	o.Lock()
+	o.closing = true
+	o.cond.Broadcast()
+	controlled := o.controlled
+	o.Unlock()
+	if controlled {
+		o.control.Close()
+	}
+	o.Finish(nil)
+}
+
+//NotifyError forwards the error to the error channel without blocking
+func (o *observerI) NotifyError(err error) {
+	o.Lock()
+	defer o.Unlock()
+	if o.finished {
+		return
+	}
+	select {
+	case o.errors <- err:
+	default:
+	}
+}
+
+//Errors returns the channel with the forwarded errors
+func (o *observerI) Errors() <-chan error {
+	return o.errors
 }
 
 //Subscribe returns a new subscriber to access values and listens for events
 func (o *observerI) Subscribe() Subscriber {
+	return o.SubscribeWithPolicy(Policy{})
+}
+
+//SubscribeWithPolicy returns a new subscriber with bounded lag
+func (o *observerI) SubscribeWithPolicy(p Policy) Subscriber {
+	o.Lock()
+	defer o.Unlock()
+	return o.subscribe(o.state, p)
+}
+
+//SubscribeWithReplay returns a new subscriber that starts with up to n recent values
+func (o *observerI) SubscribeWithReplay(n int) Subscriber {
+	o.Lock()
+	defer o.Unlock()
+	if n < 0 {
+		n = 0
+	}
+	start := o.tail
+	for skip := int(o.state.Seq-o.tail.Seq) - n; skip > 0; skip-- {
+		start = start.Next
+	}
+	return o.subscribe(start, Policy{})
+}
+
+//Replay sets the number of recent values that are kept for replay
+func (o *observerI) Replay(n int) {
+	o.Lock()
+	defer o.Unlock()
+	if n < 0 {
+		n = 0
+	}
+	o.replay = n
+	o.trim()
+}
+
+//Latest returns the most recent value
+func (o *observerI) Latest() (interface{}, bool) {
+	o.RLock()
+	defer o.RUnlock()
+	if o.latest == nil {
+		return nil, false
+	}
+	return o.latest.Value, true
+}
+
+//trim releases the values that are no longer needed for replay
+func (o *observerI) trim() {
+	for int(o.state.Seq-o.tail.Seq) > o.replay {
+		o.tail = o.tail.Next
+	}
+}
+
+//subscribe creates and registers a new subscriber starting at the given state.
+//The caller must hold the lock.
+func (o *observerI) subscribe(start *state, p Policy) *subscriber {
+	s := &subscriber{state: start, policy: p, o: o}
+	o.count++
+	//only subscribers with bounded lag are kept by the observer
+	//so that abandoned subscribers can be garbage collected.
+	//The others leave when they are collected without Close.
+	if p.MaxLag > 0 && !o.finished {
+		o.subs[s] = struct{}{}
+	} else {
+		runtime.SetFinalizer(s, (*subscriber).release)
+	}
+	return s
+}
+
+//Unsubscribe detaches the subscriber from the observer
+func (o *observerI) Unsubscribe(s Subscriber) {
+	if sub, ok := s.(*subscriber); ok && sub.o == o {
+		sub.Close()
+	}
+}
+
+//Subscribers returns the number of active subscribers
+func (o *observerI) Subscribers() int {
 	o.RLock()
 	defer o.RUnlock()
-	return &subscriber{state: o.state}
+	return o.count
+}
+
+//OnIdle registers a function that is called when the last subscriber leaves
+func (o *observerI) OnIdle(fn func()) {
+	o.Lock()
+	defer o.Unlock()
+	o.onIdle = append(o.onIdle, fn)
+}
+
+//leave removes the subscriber and calls the idle hooks
+//when the last subscriber has left
+func (o *observerI) leave(s *subscriber) {
+	o.Lock()
+	delete(o.subs, s)
+	o.count--
+	var hooks []func()
+	if o.count == 0 {
+		hooks = append(hooks, o.onIdle...)
+	}
+	o.cond.Broadcast()
+	o.Unlock()
+	for _, fn := range hooks {
+		fn()
+	}
 }
 
-//Control
+//Control returns the channels of the run loop.
+//Close waits for the run loop only if Control was called.
 func (o *observerI) Control() control {
+	o.Lock()
+	o.controlled = true
+	o.Unlock()
 	return o.control
 }
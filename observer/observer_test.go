@@ -0,0 +1,137 @@
+package observer_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/konimarti/flow/observer"
+)
+
+func TestNotify(t *testing.T) {
+	o := observer.NewObserver()
+	sub := o.Subscribe()
+
+	for i := 0; i < 3; i++ {
+		o.Notify(i)
+	}
+
+	for i := 0; i < 3; i++ {
+		select {
+		case <-sub.C():
+			if v := sub.Value(); v != i {
+				t.Errorf("Got %v. Expected %v", v, i)
+			}
+		default:
+			t.Fatal("value should be available")
+		}
+	}
+}
+
+func TestFinish(t *testing.T) {
+	o := observer.NewObserver()
+	sub := o.Subscribe()
+
+	o.Notify(1)
+	o.Finish(errors.New("end"))
+	o.Notify(2)
+
+	if sub.Err() != nil {
+		t.Error("error should only be visible at the end of the stream")
+	}
+
+	var values []interface{}
+	for done := false; !done; {
+		select {
+		case <-time.After(1 * time.Second):
+			t.Fatal("timed out")
+		case <-sub.C():
+			values = append(values, sub.Value())
+		case <-sub.Done():
+			done = true
+		}
+	}
+	if len(values) != 1 || values[0] != 1 {
+		t.Errorf("Got %v. Expected [1]", values)
+	}
+	if sub.Err() == nil || sub.Err().Error() != "end" {
+		t.Errorf("Got error %v. Expected end", sub.Err())
+	}
+
+	// subscribers after finishing are closed immediately
+	select {
+	case <-o.Subscribe().Done():
+	default:
+		t.Error("new subscriber should be done")
+	}
+}
+
+func TestCloseWithoutRunLoop(t *testing.T) {
+	for _, finish := range []bool{true, false} {
+		closeWithoutRunLoop(t, finish)
+	}
+}
+
+func closeWithoutRunLoop(t *testing.T, finish bool) {
+	o := observer.NewObserver()
+	sub := o.Subscribe()
+	if finish {
+		o.Finish(nil)
+	}
+
+	ch := make(chan bool, 1)
+	go func() {
+		o.Close()
+		ch <- true
+	}()
+
+	select {
+	case <-time.After(2 * time.Second):
+		t.Fatalf("Close blocked (finished %v)", finish)
+	case <-ch:
+	}
+
+	select {
+	case <-sub.Done():
+	default:
+		t.Error("subscriber should be done")
+	}
+}
+
+func TestCloseStopsRunLoop(t *testing.T) {
+	o := observer.NewObserver()
+	control := o.Control()
+	stopped := make(chan bool, 1)
+	go func() {
+		<-control.C
+		stopped <- true
+		control.D <- true
+	}()
+
+	o.Close()
+	select {
+	case <-stopped:
+	default:
+		t.Error("Close should wait for the run loop")
+	}
+}
+
+func TestNotifyError(t *testing.T) {
+	o := observer.NewObserver()
+	for i := 0; i < 100; i++ {
+		o.NotifyError(errors.New("fail"))
+	}
+	o.Finish(nil)
+	o.NotifyError(errors.New("after finish"))
+
+	var n int
+	for err := range o.Errors() {
+		if err.Error() != "fail" {
+			t.Errorf("Got %v. Expected fail", err)
+		}
+		n++
+	}
+	if n == 0 || n >= 100 {
+		t.Errorf("Got %d errors. Expected buffered errors only", n)
+	}
+}
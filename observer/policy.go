@@ -0,0 +1,57 @@
+package observer
+
+//Overflow defines what happens when a subscriber
+//lags behind by more than the maximum number of values
+type Overflow int
+
+const (
+	//DropOldest drops the oldest unread values
+	DropOldest Overflow = iota
+	//SkipToLatest drops all unread values except the latest one
+	SkipToLatest
+	//Block blocks the publisher until the subscriber has caught up
+	Block
+)
+
+//Policy bounds the memory that a slow subscriber can hold on to.
+//A MaxLag of zero means that the subscriber receives all values
+//without limitation.
+type Policy struct {
+	MaxLag   int
+	Overflow Overflow
+}
+
+//enforce applies the policy after a new value has been published
+//and counts the dropped values
+func (s *subscriber) enforce(head *state) {
+	s.Lock()
+	defer s.Unlock()
+	if s.closed {
+		return
+	}
+	lag := int(head.Seq - s.state.Seq)
+	if lag <= s.policy.MaxLag {
+		return
+	}
+	var n int
+	switch s.policy.Overflow {
+	case DropOldest:
+		n = lag - s.policy.MaxLag
+	case SkipToLatest:
+		n = lag - 1
+	}
+	for i := 0; i < n; i++ {
+		s.state = s.state.Next
+	}
+	s.dropped += uint64(n)
+}
+
+//lagging returns true if the subscriber blocks the publisher
+func (s *subscriber) lagging(head *state) bool {
+	if s.policy.Overflow != Block {
+		return false
+	}
+	s.Lock()
+	defer s.Unlock()
+	return !s.closed && int(head.Seq-s.state.Seq) >= s.policy.MaxLag
+}
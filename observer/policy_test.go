@@ -0,0 +1,143 @@
+package observer_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/konimarti/flow/observer"
+)
+
+func receive(t *testing.T, sub observer.Subscriber) []interface{} {
+	var values []interface{}
+	for {
+		select {
+		case <-sub.C():
+			values = append(values, sub.Value())
+		default:
+			return values
+		}
+	}
+}
+
+func TestPolicy(t *testing.T) {
+	var config = []struct {
+		Name    string
+		Policy  observer.Policy
+		Want    []interface{}
+		Dropped uint64
+	}{
+		{
+			Name:    "Unbounded",
+			Policy:  observer.Policy{},
+			Want:    []interface{}{0, 1, 2, 3, 4, 5},
+			Dropped: 0,
+		},
+		{
+			Name:    "DropOldest",
+			Policy:  observer.Policy{MaxLag: 2, Overflow: observer.DropOldest},
+			Want:    []interface{}{4, 5},
+			Dropped: 4,
+		},
+		{
+			Name:    "SkipToLatest",
+			Policy:  observer.Policy{MaxLag: 4, Overflow: observer.SkipToLatest},
+			Want:    []interface{}{4, 5},
+			Dropped: 4,
+		},
+	}
+
+	for _, cfg := range config {
+		o := observer.NewObserver()
+		sub := o.SubscribeWithPolicy(cfg.Policy)
+		for i := 0; i < 6; i++ {
+			o.Notify(i)
+		}
+		values := receive(t, sub)
+		if len(values) != len(cfg.Want) {
+			t.Fatalf("%s: Got %v. Expected %v", cfg.Name, values, cfg.Want)
+		}
+		for i := range values {
+			if values[i] != cfg.Want[i] {
+				t.Errorf("%s: Got %v. Expected %v", cfg.Name, values, cfg.Want)
+			}
+		}
+		if sub.Dropped() != cfg.Dropped {
+			t.Errorf("%s: Got %d dropped. Expected %d", cfg.Name, sub.Dropped(), cfg.Dropped)
+		}
+	}
+}
+
+func TestPolicyBlock(t *testing.T) {
+	o := observer.NewObserver()
+	sub := o.SubscribeWithPolicy(observer.Policy{MaxLag: 2, Overflow: observer.Block})
+
+	published := make(chan int, 10)
+	go func() {
+		for i := 0; i < 4; i++ {
+			o.Notify(i)
+			published <- i
+		}
+	}()
+
+	//receive waits for a value from ch
+	receive := func(ch <-chan int, want int) {
+		select {
+		case <-time.After(1 * time.Second):
+			t.Fatalf("timed out waiting for %d", want)
+		case v := <-ch:
+			if v != want {
+				t.Errorf("Got %v. Expected %v", v, want)
+			}
+		}
+	}
+
+	// the publisher blocks after 2 values and
+	// publishes the next one whenever a value is read
+	receive(published, 0)
+	receive(published, 1)
+	for i := 0; i < 4; i++ {
+		select {
+		case v := <-published:
+			t.Fatalf("Got published %d before %d was read. Expected publisher to block", v, i)
+		default:
+		}
+		select {
+		case <-time.After(1 * time.Second):
+			t.Fatal("timed out")
+		case <-sub.C():
+			if v := sub.Value(); v != i {
+				t.Errorf("Got %v. Expected %v", v, i)
+			}
+		}
+		if i+2 < 4 {
+			receive(published, i+2)
+		}
+	}
+	if sub.Dropped() != 0 {
+		t.Errorf("blocking subscriber should not drop values")
+	}
+}
+
+func TestPolicyBlockClose(t *testing.T) {
+	o := observer.NewObserver()
+	o.SubscribeWithPolicy(observer.Policy{MaxLag: 1, Overflow: observer.Block})
+
+	notified := make(chan bool)
+	done := make(chan bool)
+	go func() {
+		o.Notify(1)
+		notified <- true
+		// blocks until Finish
+		o.Notify(2)
+		done <- true
+	}()
+
+	<-notified
+	o.Finish(nil)
+
+	select {
+	case <-time.After(1 * time.Second):
+		t.Fatal("Finish should release a blocked publisher")
+	case <-done:
+	}
+}
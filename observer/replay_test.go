@@ -0,0 +1,78 @@
+package observer_test
+
+import (
+	"testing"
+
+	"github.com/konimarti/flow/observer"
+)
+
+func TestLatest(t *testing.T) {
+	o := observer.NewObserver()
+	if _, ok := o.Latest(); ok {
+		t.Error("new observer should not have a latest value")
+	}
+	o.Notify(1)
+	o.Notify(2)
+	if v, ok := o.Latest(); !ok || v != 2 {
+		t.Errorf("Got %v. Expected 2", v)
+	}
+}
+
+func TestSubscribeWithReplay(t *testing.T) {
+	var config = []struct {
+		Name   string
+		Replay int
+		N      int
+		Want   []interface{}
+	}{
+		{"Default", 1, 3, []interface{}{4, 5}},
+		{"Three", 3, 3, []interface{}{2, 3, 4, 5}},
+		{"Fewer", 3, 1, []interface{}{4, 5}},
+		{"More", 3, 10, []interface{}{2, 3, 4, 5}},
+		{"None", 0, 3, []interface{}{5}},
+		{"Zero", 3, 0, []interface{}{5}},
+		{"Negative", 3, -1, []interface{}{5}},
+		{"Unset", -1, 3, []interface{}{4, 5}},
+	}
+
+	for _, cfg := range config {
+		o := observer.NewObserver()
+		if cfg.Replay >= 0 {
+			o.Replay(cfg.Replay)
+		}
+		for i := 0; i < 5; i++ {
+			o.Notify(i)
+		}
+		sub := o.SubscribeWithReplay(cfg.N)
+		o.Notify(5)
+
+		values := receive(t, sub)
+		if len(values) != len(cfg.Want) {
+			t.Fatalf("%s: Got %v. Expected %v", cfg.Name, values, cfg.Want)
+		}
+		for i := range values {
+			if values[i] != cfg.Want[i] {
+				t.Errorf("%s: Got %v. Expected %v", cfg.Name, values, cfg.Want)
+			}
+		}
+	}
+}
+
+func TestReplayAfterFinish(t *testing.T) {
+	o := observer.NewObserver()
+	o.Replay(2)
+	o.Notify(1)
+	o.Notify(2)
+	o.Finish(nil)
+
+	sub := o.SubscribeWithReplay(2)
+	values := receive(t, sub)
+	if len(values) != 2 || values[0] != 1 || values[1] != 2 {
+		t.Errorf("Got %v. Expected [1 2]", values)
+	}
+	select {
+	case <-sub.Done():
+	default:
+		t.Error("subscriber should be done after the replayed values")
+	}
+}
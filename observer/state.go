@@ -3,11 +3,14 @@ package observer
 // state
 type state struct {
 	C     chan struct{}
+	E     chan struct{}
 	Value interface{}
+	Err   error
+	Seq   uint64
 	Next  *state
 }
 
 //NewState creats a new state
 func NewState() *state {
-	return &state{C: make(chan struct{})}
+	return &state{C: make(chan struct{}), E: make(chan struct{})}
 }
@@ -1,32 +1,119 @@
 package observer
 
+import (
+	"sync"
+)
+
 // Subscriber describes the interface
 // returned by subscribing to an observer
 type Subscriber interface {
 	C() chan struct{}
 	Value() interface{}
+	Done() <-chan struct{}
+	Err() error
+	//Dropped returns the number of values that were dropped
+	//because the subscriber lagged behind.
+	Dropped() uint64
+	//Close detaches the subscriber from the observer.
+	//Afterwards, Done is closed and no more values are received.
+	Close()
 }
 
 type subscriber struct {
-	state *state
+	sync.Mutex //embedded
+	state      *state
+	policy     Policy
+	dropped    uint64
+	closed     bool
+	o          *observerI
 }
 
 //Value return the current value
 func (s *subscriber) Value() interface{} {
+	s.Lock()
 	v := s.v()
 	s.next()
+	s.Unlock()
+	if s.policy.Overflow == Block {
+		s.o.Lock()
+		s.o.cond.Broadcast()
+		s.o.Unlock()
+	}
 	return v
 }
 
 //C returns a channel and signals if Value() can be called
 func (s *subscriber) C() chan struct{} {
+	s.Lock()
+	defer s.Unlock()
 	return s.state.C
 }
 
+//Done returns a channel that is closed when the observer has finished
+//and all previous values have been received
+func (s *subscriber) Done() <-chan struct{} {
+	s.Lock()
+	defer s.Unlock()
+	return s.state.E
+}
+
+//Err returns the error that ended the stream.
+//It returns nil while the stream is running or if it ended normally.
+func (s *subscriber) Err() error {
+	s.Lock()
+	defer s.Unlock()
+	select {
+	case <-s.state.E:
+		return s.state.Err
+	default:
+		return nil
+	}
+}
+
+//Dropped returns the number of dropped values
+func (s *subscriber) Dropped() uint64 {
+	s.Lock()
+	defer s.Unlock()
+	return s.dropped
+}
+
+//Close detaches the subscriber from the observer
+func (s *subscriber) Close() {
+	s.Lock()
+	if s.closed {
+		s.Unlock()
+		return
+	}
+	s.closed = true
+	s.state = closedState()
+	s.Unlock()
+	s.o.leave(s)
+}
+
+//release lets a subscriber that was garbage collected without Close leave the observer
+func (s *subscriber) release() {
+	s.Lock()
+	closed := s.closed
+	s.closed = true
+	s.Unlock()
+	if !closed {
+		s.o.leave(s)
+	}
+}
+
+//closedState returns the terminal state of a closed subscriber
+func closedState() *state {
+	st := NewState()
+	close(st.E)
+	return st
+}
+
 func (s *subscriber) v() interface{} {
 	return s.state.Value
 }
 
 func (s *subscriber) next() {
-	s.state = s.state.Next
+	if s.state.Next != nil {
+		s.state = s.state.Next
+	}
 }
@@ -0,0 +1,106 @@
+package observer_test
+
+import (
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/konimarti/flow/observer"
+)
+
+func TestSubscriberClose(t *testing.T) {
+	o := observer.NewObserver()
+	idle := make(chan bool, 1)
+	o.OnIdle(func() { idle <- true })
+
+	s1 := o.Subscribe()
+	s2 := o.SubscribeWithPolicy(observer.Policy{MaxLag: 1, Overflow: observer.Block})
+	if n := o.Subscribers(); n != 2 {
+		t.Fatalf("Got %d subscribers. Expected 2", n)
+	}
+
+	s1.Close()
+	s1.Close()
+	if n := o.Subscribers(); n != 1 {
+		t.Fatalf("Got %d subscribers. Expected 1", n)
+	}
+	select {
+	case <-s1.Done():
+	default:
+		t.Error("closed subscriber should be done")
+	}
+	select {
+	case <-idle:
+		t.Error("idle hook should not be called while subscribers are left")
+	default:
+	}
+
+	// the blocking subscriber must not block the publisher after it left
+	o.Unsubscribe(s2)
+	published := make(chan bool)
+	go func() {
+		o.Notify(1)
+		o.Notify(2)
+		published <- true
+	}()
+	select {
+	case <-time.After(1 * time.Second):
+		t.Fatal("publisher blocked by an unsubscribed subscriber")
+	case <-published:
+	}
+
+	select {
+	case <-idle:
+	default:
+		t.Error("idle hook should be called when the last subscriber leaves")
+	}
+	if n := o.Subscribers(); n != 0 {
+		t.Errorf("Got %d subscribers. Expected 0", n)
+	}
+}
+
+func TestUnsubscribeReleasesBlockedPublisher(t *testing.T) {
+	o := observer.NewObserver()
+	s := o.SubscribeWithPolicy(observer.Policy{MaxLag: 1, Overflow: observer.Block})
+
+	notified := make(chan bool)
+	published := make(chan bool)
+	go func() {
+		o.Notify(1)
+		notified <- true
+		// blocks until the subscriber is closed
+		o.Notify(2)
+		published <- true
+	}()
+
+	<-notified
+	s.Close()
+
+	select {
+	case <-time.After(1 * time.Second):
+		t.Fatal("Close should release a blocked publisher")
+	case <-published:
+	}
+}
+
+func TestAbandonedSubscriber(t *testing.T) {
+	o := observer.NewObserver()
+	idle := make(chan bool, 1)
+	o.OnIdle(func() { idle <- true })
+	func() {
+		o.Subscribe()
+	}()
+	if n := o.Subscribers(); n != 1 {
+		t.Fatalf("Got %d subscribers. Expected 1", n)
+	}
+
+	runtime.GC()
+	select {
+	case <-time.After(2 * time.Second):
+		t.Fatal("abandoned subscriber should leave when it is garbage collected")
+	case <-idle:
+	}
+	if n := o.Subscribers(); n != 0 {
+		t.Errorf("Got %d subscribers. Expected 0", n)
+	}
+}
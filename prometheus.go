@@ -0,0 +1,319 @@
+package flow
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/konimarti/flow/clock"
+	"github.com/konimarti/flow/filters"
+	"github.com/konimarti/flow/observer"
+)
+
+//Prometheus implements the Source interface and scrapes an endpoint in the
+//Prometheus text exposition format in regular intervals.
+//Every sample of the series that match the Selector is passed to the filters as a
+//filters.Event with the value as float64, the series (e.g. `up{job="api"}`) as Key and
+//the labels as Meta. The time of the sample or the time of the scrape is used as event time.
+//Failed scrapes and invalid responses are handled according to the Policy.
+type Prometheus struct {
+	URL    string
+	Header http.Header
+	//Selector selects the series by metric name and label matchers,
+	//e.g. `http_requests_total{method="GET",code=~"2.."}`.
+	//The operators =, !=, =~ and !~ are supported. All series are selected if empty.
+	Selector string
+	//Refresh is the scrape interval. The flow ends with an error if it is not positive.
+	Refresh time.Duration
+	//Timeout is the timeout of a scrape (default Refresh)
+	Timeout time.Duration
+	//Client is the HTTP client (default http.DefaultClient)
+	Client *http.Client
+	Policy ErrorPolicy
+}
+
+//Run scrapes the endpoint in regular intervals
+func (p *Prometheus) Run(nf filters.Filter) observer.Observer {
+	return p.RunContext(context.Background(), nf)
+}
+
+//RunContext scrapes the endpoint in regular intervals until the context is done.
+//The flow ends with an error if the Selector or the Refresh is invalid.
+func (p *Prometheus) RunContext(ctx context.Context, nf filters.Filter) observer.Observer {
+	return p.job(nf).run(ctx)
+}
+
+//job returns the flow that scrapes the endpoint in regular intervals
+func (p *Prometheus) job(nf filters.Filter) job {
+	return job{nf: nf, policy: p.Policy, produce: func(ctx context.Context, emit emitFunc) error {
+		sel, err := parseSelector(p.Selector)
+		if err != nil {
+			return err
+		}
+		if p.Refresh <= 0 {
+			return fmt.Errorf("flow: prometheus: refresh must be positive, got %v", p.Refresh)
+		}
+		timeout := p.Timeout
+		if timeout <= 0 {
+			timeout = p.Refresh
+		}
+		header := http.Header{"Accept": {"text/plain;version=0.0.4"}}
+		for key, values := range p.Header {
+			header[key] = values
+		}
+		c := clock.FromContext(ctx)
+		ticker := c.NewTicker(p.Refresh)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C():
+				body, err := fetch(ctx, p.Client, http.MethodGet, p.URL, header, timeout)
+				if err != nil {
+					if !emit(nil, err) {
+						return nil
+					}
+					continue
+				}
+				samples, err := parseExposition(body)
+				if err != nil {
+					if !emit(nil, err) {
+						return nil
+					}
+					continue
+				}
+				now := c.Now()
+				for _, s := range samples {
+					if !sel.matches(s) {
+						continue
+					}
+					if s.time.IsZero() {
+						s.time = now
+					}
+					if !emit(s.event(), nil) {
+						return nil
+					}
+				}
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+	}}
+}
+
+//promSample is a sample of the exposition format
+type promSample struct {
+	name   string
+	labels map[string]string
+	value  float64
+	time   time.Time
+}
+
+//event returns the sample as filters.Event
+func (s promSample) event() filters.Event {
+	names := make([]string, 0, len(s.labels))
+	for name := range s.labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	pairs := make([]string, len(names))
+	for i, name := range names {
+		pairs[i] = fmt.Sprintf("%s=%q", name, s.labels[name])
+	}
+	key := s.name
+	if len(pairs) > 0 {
+		key += "{" + strings.Join(pairs, ",") + "}"
+	}
+	return filters.Event{Time: s.time, Key: key, Meta: s.labels, Value: s.value}
+}
+
+//parseExposition parses the samples of the Prometheus text format
+func parseExposition(body []byte) ([]promSample, error) {
+	var samples []promSample
+	scanner := bufio.NewScanner(bytes.NewReader(body))
+	scanner.Buffer(make([]byte, 4096), maxRecordSize)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || line[0] == '#' {
+			continue
+		}
+		s, err := parseSample(line)
+		if err != nil {
+			return nil, fmt.Errorf("flow: line %d: %v", n, err)
+		}
+		samples = append(samples, s)
+	}
+	return samples, scanner.Err()
+}
+
+//parseSample parses a line like `name{label="value"} 1.5 1700000000000`
+func parseSample(line string) (promSample, error) {
+	s := promSample{labels: make(map[string]string)}
+	i := strings.IndexAny(line, "{ \t")
+	if i <= 0 {
+		return s, fmt.Errorf("invalid sample %q", line)
+	}
+	s.name, line = line[:i], line[i:]
+	if line[0] == '{' {
+		end, err := parseLabels(line[1:], func(name, op, value string) error {
+			if op != "=" {
+				return fmt.Errorf("invalid label %s%s%q", name, op, value)
+			}
+			s.labels[name] = value
+			return nil
+		})
+		if err != nil {
+			return s, err
+		}
+		line = line[end+1:]
+	}
+
+	fields := strings.Fields(line)
+	if len(fields) < 1 || len(fields) > 2 {
+		return s, fmt.Errorf("invalid sample %q", line)
+	}
+	value, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return s, err
+	}
+	s.value = value
+	if len(fields) == 2 {
+		ms, err := strconv.ParseInt(fields[1], 10, 64)
+		if err != nil {
+			return s, err
+		}
+		s.time = time.Unix(0, ms*int64(time.Millisecond))
+	}
+	return s, nil
+}
+
+//parseLabels parses the label pairs after the opening brace and calls fn for every pair.
+//It returns the length of the labels including the closing brace.
+func parseLabels(text string, fn func(name, op, value string) error) (int, error) {
+	i := 0
+	skip := func() {
+		for i < len(text) && (text[i] == ' ' || text[i] == '\t') {
+			i++
+		}
+	}
+	for {
+		skip()
+		if i < len(text) && text[i] == '}' {
+			return i + 1, nil
+		}
+		start := i
+		for i < len(text) && strings.IndexByte("=!~ \t", text[i]) < 0 {
+			i++
+		}
+		name := text[start:i]
+		skip()
+		start = i
+		for i < len(text) && strings.IndexByte("=!~", text[i]) >= 0 {
+			i++
+		}
+		op := text[start:i]
+		skip()
+		if name == "" || op == "" || i >= len(text) || text[i] != '"' {
+			return 0, fmt.Errorf("invalid labels {%s", text)
+		}
+		var value strings.Builder
+		for i++; i < len(text) && text[i] != '"'; i++ {
+			if text[i] == '\\' && i+1 < len(text) {
+				i++
+				switch text[i] {
+				case 'n':
+					value.WriteByte('\n')
+				default:
+					value.WriteByte(text[i])
+				}
+				continue
+			}
+			value.WriteByte(text[i])
+		}
+		if i >= len(text) {
+			return 0, fmt.Errorf("unterminated label value {%s", text)
+		}
+		i++
+		if err := fn(name, op, value.String()); err != nil {
+			return 0, err
+		}
+		skip()
+		if i < len(text) && text[i] == ',' {
+			i++
+		}
+	}
+}
+
+//selector selects series by metric name and label matchers
+type selector struct {
+	name     string
+	matchers []matcher
+}
+
+type matcher struct {
+	label  string
+	value  string
+	re     *regexp.Regexp
+	negate bool
+}
+
+//parseSelector parses a selector like `name{label="value",other=~"regexp"}`
+func parseSelector(text string) (*selector, error) {
+	sel := &selector{}
+	text = strings.TrimSpace(text)
+	i := strings.IndexByte(text, '{')
+	if i < 0 {
+		sel.name = text
+		return sel, nil
+	}
+	sel.name = strings.TrimSpace(text[:i])
+	end, err := parseLabels(text[i+1:], func(label, op, value string) error {
+		m := matcher{label: label, value: value}
+		switch op {
+		case "=":
+		case "!=":
+			m.negate = true
+		case "=~", "!~":
+			re, err := regexp.Compile("^(?:" + value + ")$")
+			if err != nil {
+				return err
+			}
+			m.re, m.negate = re, op == "!~"
+		default:
+			return fmt.Errorf("invalid operator %s", op)
+		}
+		sel.matchers = append(sel.matchers, m)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("flow: selector %s: %v", text, err)
+	}
+	if strings.TrimSpace(text[i+1+end:]) != "" {
+		return nil, fmt.Errorf("flow: selector %s: unexpected %q", text, text[i+1+end:])
+	}
+	return sel, nil
+}
+
+//matches returns true if the sample belongs to a selected series
+func (sel *selector) matches(s promSample) bool {
+	if sel.name != "" && sel.name != s.name {
+		return false
+	}
+	for _, m := range sel.matchers {
+		value := s.labels[m.label]
+		ok := value == m.value
+		if m.re != nil {
+			ok = m.re.MatchString(value)
+		}
+		if ok == m.negate {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,102 @@
+package flow_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/konimarti/flow"
+	"github.com/konimarti/flow/clock"
+	"github.com/konimarti/flow/filters"
+)
+
+const exposition = `# HELP http_requests_total The total number of HTTP requests.
+# TYPE http_requests_total counter
+http_requests_total{method="post",code="200"} 1027 1395066363000
+http_requests_total{method="post",code="400"}    3 1395066363000
+http_requests_total{method="get",code="200",path="C:\\DIR\\"} 42
+
+# A metric without labels
+up 1
+temperature{sensor="a \"quoted\" name"} -Inf
+`
+
+func TestPrometheus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(exposition))
+	}))
+	defer server.Close()
+
+	c := clock.NewManual(time.Unix(1500000000, 0))
+	ctx := clock.NewContext(context.Background(), c)
+	observer := flow.NewWithContext(ctx, &filters.None{}, &flow.Prometheus{
+		URL:      server.URL,
+		Selector: `http_requests_total{code=~"2..", method!="put"}`,
+		Refresh:  time.Second,
+	})
+	defer observer.Close()
+	subscriber := observer.Subscribe()
+
+	c.BlockUntil(1)
+	c.Advance(time.Second)
+
+	var events []filters.Event
+	for len(events) < 2 {
+		select {
+		case <-subscriber.C():
+			events = append(events, subscriber.Value().(filters.Event))
+		case err := <-observer.Errors():
+			t.Fatal(err)
+		case <-time.After(2 * time.Second):
+			t.Fatal("timed out waiting for samples")
+		}
+	}
+
+	if e := events[0]; e.Value != 1027.0 || e.Key != `http_requests_total{code="200",method="post"}` ||
+		!e.Time.Equal(time.Unix(1395066363, 0)) {
+		t.Errorf("Got %+v. Expected first series", e)
+	}
+	if e := events[1]; e.Value != 42.0 || e.Meta["path"] != `C:\DIR\` || !e.Time.Equal(c.Now()) {
+		t.Errorf("Got %+v. Expected second series with scrape time", e)
+	}
+}
+
+func TestPrometheusInvalid(t *testing.T) {
+	observer := flow.New(&filters.None{}, &flow.Prometheus{Selector: `up{job=~"("}`, Refresh: time.Second})
+	subscriber := observer.Subscribe()
+	collect(t, subscriber)
+	if subscriber.Err() == nil {
+		t.Error("Expected error for invalid selector")
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("up{job=\"api\" 1\n"))
+	}))
+	defer server.Close()
+
+	c := clock.NewManual(time.Now())
+	ctx := clock.NewContext(context.Background(), c)
+	observer = flow.NewWithContext(ctx, &filters.None{}, &flow.Prometheus{URL: server.URL, Refresh: time.Second})
+	defer observer.Close()
+	c.BlockUntil(1)
+	c.Advance(time.Second)
+	select {
+	case err := <-observer.Errors():
+		if err == nil {
+			t.Error("Expected parse error")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for parse error")
+	}
+}
+
+func TestPrometheusInvalidRefresh(t *testing.T) {
+	_, subscriber := flow.Subscribe(&filters.None{}, &flow.Prometheus{URL: "http://localhost/metrics", Refresh: -time.Second})
+	collect(t, subscriber)
+	err := subscriber.Err()
+	if _, ok := err.(*flow.PanicError); ok || err == nil || err.Error() != "flow: prometheus: refresh must be positive, got -1s" {
+		t.Errorf("Got %v. Expected invalid refresh", err)
+	}
+}
@@ -0,0 +1,82 @@
+package flow
+
+import (
+	"bufio"
+	"context"
+	"io"
+
+	"github.com/konimarti/flow/filters"
+	"github.com/konimarti/flow/observer"
+)
+
+//maxRecordSize is the maximal length of a line
+const maxRecordSize = 1024 * 1024
+
+//Reader implements the Source interface and reads an io.Reader line by line.
+//Each line is decoded by the Decoder (default StringDecoder) and passed to the filters.
+//Empty lines are skipped. The flow ends at EOF or with the error of the reader.
+//Decoding errors are handled according to the Policy.
+type Reader struct {
+	R       io.Reader
+	Decoder Decoder
+	Policy  ErrorPolicy
+}
+
+//Run reads the lines and passes the decoded values to the filters
+func (r *Reader) Run(nf filters.Filter) observer.Observer {
+	return r.RunContext(context.Background(), nf)
+}
+
+//RunContext reads the lines and passes the decoded values to the filters until the context is done.
+//A pending read on the io.Reader is not interrupted when the context is done.
+func (r *Reader) RunContext(ctx context.Context, nf filters.Filter) observer.Observer {
+	return r.job(nf).run(ctx)
+}
+
+//job returns the flow that reads the lines and passes the decoded values to the filters
+func (r *Reader) job(nf filters.Filter) job {
+	decoder := decoderOr(r.Decoder)
+	return job{nf: nf, policy: r.Policy, produce: func(ctx context.Context, emit emitFunc) error {
+		return scan(ctx, r.R, func(line []byte) bool {
+			return emit(decoder.Decode(line))
+		})
+	}}
+}
+
+//scan reads the lines of an io.Reader and passes the non-empty lines to fn
+//until fn returns false or the context is done.
+//It returns nil at EOF and the error of the reader otherwise.
+func scan(ctx context.Context, rd io.Reader, fn func([]byte) bool) error {
+	lines := make(chan []byte)
+	errc := make(chan error, 1)
+	go func() {
+		scanner := bufio.NewScanner(rd)
+		scanner.Buffer(make([]byte, 4096), maxRecordSize)
+		for scanner.Scan() {
+			line := make([]byte, len(scanner.Bytes()))
+			copy(line, scanner.Bytes())
+			select {
+			case lines <- line:
+			case <-ctx.Done():
+				return
+			}
+		}
+		errc <- scanner.Err()
+	}()
+
+	for {
+		select {
+		case line := <-lines:
+			if len(line) == 0 {
+				continue
+			}
+			if !fn(line) {
+				return nil
+			}
+		case err := <-errc:
+			return err
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package flow_test
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/konimarti/flow"
+	"github.com/konimarti/flow/filters"
+	"github.com/konimarti/flow/observer"
+)
+
+//collect receives all values of a subscriber until the end of the stream
+func collect(t *testing.T, subscriber observer.Subscriber) []interface{} {
+	var values []interface{}
+	for {
+		select {
+		case <-time.After(1 * time.Second):
+			t.Fatal("timed out waiting for end of stream")
+		case <-subscriber.C():
+			values = append(values, subscriber.Value())
+		case <-subscriber.Done():
+			return values
+		}
+	}
+}
+
+func TestReader(t *testing.T) {
+	input := "1.0\n2.0\n\n3.0\r\n"
+	observer, subscriber := flow.Subscribe(&filters.None{}, &flow.Reader{R: strings.NewReader(input), Decoder: flow.FloatDecoder})
+	defer observer.Close()
+
+	values := collect(t, subscriber)
+	if len(values) != 3 || values[0] != 1.0 || values[1] != 2.0 || values[2] != 3.0 {
+		t.Errorf("Got %v. Expected [1 2 3]", values)
+	}
+}
+
+func TestReaderDecodeErrors(t *testing.T) {
+	input := "1.0\nabc\n3.0\n"
+
+	_, subscriber := flow.Subscribe(&filters.None{}, &flow.Reader{
+		R:       strings.NewReader(input),
+		Decoder: flow.FloatDecoder,
+		Policy:  flow.SkipErrors,
+	})
+	values := collect(t, subscriber)
+	if len(values) != 2 {
+		t.Errorf("Got %v. Expected decode error to be skipped", values)
+	}
+
+	_, subscriber = flow.Subscribe(&filters.None{}, &flow.Reader{
+		R:       strings.NewReader(input),
+		Decoder: flow.FloatDecoder,
+		Policy:  flow.StopOnError,
+	})
+	values = collect(t, subscriber)
+	if len(values) != 1 || subscriber.Err() == nil {
+		t.Errorf("Got %v and %v. Expected flow to stop at decode error", values, subscriber.Err())
+	}
+}
+
+type failingReader struct{}
+
+func (failingReader) Read(p []byte) (int, error) {
+	return 0, errors.New("read failed")
+}
+
+func TestReaderError(t *testing.T) {
+	_, subscriber := flow.Subscribe(&filters.None{}, &flow.Reader{R: failingReader{}})
+	collect(t, subscriber)
+	if err := subscriber.Err(); err == nil || err.Error() != "read failed" {
+		t.Errorf("Got %v. Expected read failed", err)
+	}
+}
+
+func TestSubscribeBeforeStart(t *testing.T) {
+	for i := 0; i < 50; i++ {
+		_, subscriber := flow.Subscribe(&filters.None{}, &flow.Reader{R: strings.NewReader("1\n2\n3\n"), Decoder: flow.FloatDecoder})
+		runtime.Gosched()
+		runtime.Gosched()
+		if values := collect(t, subscriber); len(values) != 3 {
+			t.Fatalf("Got %v. Expected [1 2 3]", values)
+		}
+	}
+
+	// the subscriber is attached to the outer flow of combined sources
+	_, subscriber := flow.Subscribe(&filters.None{}, &flow.Merge{Sources: []flow.Source{
+		&flow.Reader{R: strings.NewReader("1\n2\n")},
+		&flow.Reader{R: strings.NewReader("3\n4\n")},
+	}})
+	if values := collect(t, subscriber); len(values) != 4 {
+		t.Errorf("Got %v. Expected 4 values", values)
+	}
+}
+
+// lines is a source of another package that attaches subscribers before it starts
+type lines struct {
+	s string
+}
+
+func (l *lines) Run(nf filters.Filter) observer.Observer {
+	return flow.New(nf, &flow.Reader{R: strings.NewReader(l.s)})
+}
+
+func (l *lines) SubscribeContext(ctx context.Context, nf filters.Filter, p observer.Policy) (observer.Observer, observer.Subscriber) {
+	return flow.SubscribeWithContext(ctx, nf, &flow.Reader{R: strings.NewReader(l.s)}, p)
+}
+
+func TestSubscribeSource(t *testing.T) {
+	_, subscriber := flow.Subscribe(&filters.None{}, &lines{s: "a\nb\n"})
+	if values := collect(t, subscriber); len(values) != 2 {
+		t.Errorf("Got %v. Expected [a b]", values)
+	}
+}
@@ -0,0 +1,202 @@
+package flow
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/konimarti/flow/clock"
+	"github.com/konimarti/flow/filters"
+	"github.com/konimarti/flow/observer"
+)
+
+//Format defines the format of recorded data
+type Format int
+
+const (
+	//DetectFormat uses JSONLines if the first record starts with '{' and CSV otherwise
+	DetectFormat Format = iota
+	//CSV is comma-separated values with a header row that names the fields
+	CSV
+	//JSONLines is one JSON object per line
+	JSONLines
+)
+
+//Replay implements the Source interface and plays back recorded data.
+//Every record is passed to the filters as a filters.Event with the recorded time,
+//the value of the ValueField and the other fields as metadata.
+//Numeric CSV values are converted to float64.
+//With a Speed of 0 the records are sent as fast as possible. Otherwise, the
+//records are paced according to their timestamps, e.g. Speed 1 replays in real time
+//and Speed 10 ten times faster. Records that are out of order are sent immediately.
+//Invalid records are handled according to the Policy.
+type Replay struct {
+	R      io.Reader
+	Format Format
+	//TimeField is the name of the field with the timestamp (default "time")
+	TimeField string
+	//ValueField is the name of the field with the value (default "value")
+	ValueField string
+	//TimeLayout is the layout of the timestamps (default time.RFC3339Nano).
+	//Without a layout, numeric timestamps are parsed as seconds since the Unix epoch.
+	TimeLayout string
+	//Comma is the field delimiter of CSV records (default ',')
+	Comma  rune
+	Speed  float64
+	Policy ErrorPolicy
+}
+
+//Run plays back the records
+func (r *Replay) Run(nf filters.Filter) observer.Observer {
+	return r.RunContext(context.Background(), nf)
+}
+
+//RunContext plays back the records until the context is done
+func (r *Replay) RunContext(ctx context.Context, nf filters.Filter) observer.Observer {
+	return r.job(nf).run(ctx)
+}
+
+//job returns the flow that plays back the records
+func (r *Replay) job(nf filters.Filter) job {
+	return job{nf: nf, policy: r.Policy, produce: func(ctx context.Context, emit emitFunc) error {
+		c := clock.FromContext(ctx)
+		p := &recordParser{replay: r, format: r.Format}
+		var first, start time.Time
+		return scan(ctx, r.R, func(line []byte) bool {
+			e, ok, err := p.parse(line)
+			if err != nil {
+				return emit(nil, err)
+			}
+			if !ok {
+				return true
+			}
+			if r.Speed > 0 {
+				if first.IsZero() {
+					first, start = e.Time, c.Now()
+				}
+				offset := time.Duration(float64(e.Time.Sub(first)) / r.Speed)
+				if wait := start.Add(offset).Sub(c.Now()); wait > 0 {
+					select {
+					case <-c.After(wait):
+					case <-ctx.Done():
+						return false
+					}
+				}
+			}
+			return emit(e, nil)
+		})
+	}}
+}
+
+//recordParser parses the records of a replay
+type recordParser struct {
+	replay *Replay
+	format Format
+	header []string
+}
+
+//parse returns the event of a record.
+//It returns false for the header row of CSV data.
+func (p *recordParser) parse(line []byte) (filters.Event, bool, error) {
+	if p.format == DetectFormat {
+		p.format = CSV
+		if bytes.HasPrefix(bytes.TrimSpace(line), []byte("{")) {
+			p.format = JSONLines
+		}
+	}
+
+	fields := make(map[string]interface{})
+	switch p.format {
+	case JSONLines:
+		v, err := JSONDecoder.Decode(line)
+		if err != nil {
+			return filters.Event{}, false, err
+		}
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return filters.Event{}, false, fmt.Errorf("flow: record is not a JSON object: %s", line)
+		}
+		fields = m
+	case CSV:
+		v, err := (&CSVDecoder{Comma: p.replay.Comma}).Decode(line)
+		if err != nil {
+			return filters.Event{}, false, err
+		}
+		row := v.([]string)
+		if p.header == nil {
+			p.header = row
+			return filters.Event{}, false, nil
+		}
+		if len(row) != len(p.header) {
+			return filters.Event{}, false, fmt.Errorf("flow: record has %d fields, expected %d: %s", len(row), len(p.header), line)
+		}
+		timeField, _ := p.replay.fieldNames()
+		for i, name := range p.header {
+			if name == timeField && p.replay.TimeLayout != "" {
+				fields[name] = row[i]
+			} else if f, err := strconv.ParseFloat(strings.TrimSpace(row[i]), 64); err == nil {
+				fields[name] = f
+			} else {
+				fields[name] = row[i]
+			}
+		}
+	default:
+		return filters.Event{}, false, fmt.Errorf("flow: unknown format %d", p.format)
+	}
+
+	timeField, valueField := p.replay.fieldNames()
+	ts, ok := fields[timeField]
+	if !ok {
+		return filters.Event{}, false, fmt.Errorf("flow: record has no field %q: %s", timeField, line)
+	}
+	t, err := parseTime(ts, p.replay.TimeLayout)
+	if err != nil {
+		return filters.Event{}, false, err
+	}
+	value, ok := fields[valueField]
+	if !ok {
+		return filters.Event{}, false, fmt.Errorf("flow: record has no field %q: %s", valueField, line)
+	}
+
+	e := filters.Event{Time: t, Value: value}
+	for name, v := range fields {
+		if name == timeField || name == valueField {
+			continue
+		}
+		if e.Meta == nil {
+			e.Meta = make(map[string]string)
+		}
+		e.Meta[name] = fmt.Sprint(v)
+	}
+	return e, true, nil
+}
+
+func (r *Replay) fieldNames() (string, string) {
+	timeField, valueField := r.TimeField, r.ValueField
+	if timeField == "" {
+		timeField = "time"
+	}
+	if valueField == "" {
+		valueField = "value"
+	}
+	return timeField, valueField
+}
+
+//parseTime parses a timestamp with the layout or as seconds since the Unix epoch
+func parseTime(v interface{}, layout string) (time.Time, error) {
+	switch t := v.(type) {
+	case float64:
+		sec := int64(t)
+		return time.Unix(sec, int64((t-float64(sec))*1e9)), nil
+	case string:
+		if layout == "" {
+			layout = time.RFC3339Nano
+		}
+		return time.Parse(layout, strings.TrimSpace(t))
+	}
+	return time.Time{}, fmt.Errorf("flow: invalid timestamp %v", v)
+}
@@ -0,0 +1,79 @@
+package flow_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/konimarti/flow"
+	"github.com/konimarti/flow/clock"
+	"github.com/konimarti/flow/filters"
+	"github.com/konimarti/flow/observer"
+)
+
+func TestReplayCSV(t *testing.T) {
+	input := "time,value,sensor\n1700000000,1.5,a\n1700000001.5,2.5,b\n"
+	observer, subscriber := flow.Subscribe(&filters.None{}, &flow.Replay{R: strings.NewReader(input)})
+	defer observer.Close()
+
+	values := collect(t, subscriber)
+	if len(values) != 2 {
+		t.Fatalf("Got %v. Expected 2 events", values)
+	}
+	e := values[1].(filters.Event)
+	if e.Value != 2.5 || e.Meta["sensor"] != "b" || !e.Time.Equal(time.Unix(1700000001, 5e8)) {
+		t.Errorf("Got %+v. Expected event with value 2.5, sensor b and recorded time", e)
+	}
+}
+
+func TestReplayJSONLines(t *testing.T) {
+	input := `{"ts":"2024-01-01T10:00:00Z","v":1}
+{"ts":"2024-01-01T10:00:01Z","v":2}
+not json
+{"ts":"2024-01-01T10:00:02Z","v":3}
+`
+	observer, subscriber := flow.Subscribe(&filters.None{}, &flow.Replay{
+		R:          strings.NewReader(input),
+		TimeField:  "ts",
+		ValueField: "v",
+		Policy:     flow.SkipErrors,
+	})
+	defer observer.Close()
+
+	values := collect(t, subscriber)
+	if len(values) != 3 {
+		t.Fatalf("Got %v. Expected 3 events", values)
+	}
+	e := values[2].(filters.Event)
+	if e.Value != 3.0 || e.Time != time.Date(2024, 1, 1, 10, 0, 2, 0, time.UTC) {
+		t.Errorf("Got %+v. Expected third record", e)
+	}
+}
+
+func TestReplaySpeed(t *testing.T) {
+	input := "time,value\n100,1\n101,2\n103,3\n"
+	c := clock.NewManual(time.Unix(0, 0))
+	ctx := clock.NewContext(context.Background(), c)
+
+	observer, subscriber := flow.SubscribeWithContext(ctx, &filters.Unwrap{}, &flow.Replay{R: strings.NewReader(input), Speed: 2}, observer.Policy{})
+	defer observer.Close()
+
+	expect(t, subscriber, 1.0)
+
+	// the second record is due after 500ms
+	c.BlockUntil(1)
+	c.Advance(400 * time.Millisecond)
+	select {
+	case <-subscriber.C():
+		t.Fatal("record replayed too early")
+	case <-time.After(20 * time.Millisecond):
+	}
+	c.Advance(100 * time.Millisecond)
+	expect(t, subscriber, 2.0)
+
+	// the third record is due after 1.5s
+	c.BlockUntil(1)
+	c.Advance(time.Second)
+	expect(t, subscriber, 3.0)
+}
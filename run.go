@@ -0,0 +1,170 @@
+package flow
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/konimarti/flow/clock"
+	"github.com/konimarti/flow/filters"
+	"github.com/konimarti/flow/observer"
+)
+
+//emitFunc passes a value or the error of the source through the filters.
+//It returns false when the flow has been stopped.
+type emitFunc func(interface{}, error) bool
+
+//produceFunc generates the values of a source until
+//the context is done or the source is exhausted.
+type produceFunc func(ctx context.Context, emit emitFunc) error
+
+//job is the flow of a source of this package before it is started.
+//Its values are passed through the filter nf and its errors are handled according to the policy.
+//A polling source uses idle to pause while the flow has no subscribers.
+type job struct {
+	nf      filters.Filter
+	policy  ErrorPolicy
+	produce produceFunc
+	idle    *idler
+}
+
+//idler pauses a polling source after the last subscriber of its flow has left
+//until a new subscriber arrives
+type idler struct {
+	sync.Mutex
+	o      observer.Observer
+	paused bool
+}
+
+//watch registers the idle hook on the observer before the flow starts
+func (i *idler) watch(o observer.Observer) {
+	i.o = o
+	o.OnIdle(func() {
+		i.Lock()
+		i.paused = true
+		i.Unlock()
+	})
+}
+
+//skip returns true if the source should not poll because the flow is idle
+func (i *idler) skip() bool {
+	i.Lock()
+	defer i.Unlock()
+	if i.paused && i.o.Subscribers() > 0 {
+		i.paused = false
+	}
+	return i.paused
+}
+
+//run starts the job and returns its observer.
+//The flow is stopped when the parent context is done, the observer is closed,
+//an error occurs with the StopOnError policy or the source or a filter panics.
+func (j job) run(parent context.Context) observer.Observer {
+	o, _ := j.start(parent, nil)
+	return o
+}
+
+//start creates the observer of the job and attaches a subscriber with the policy p, if p is not nil,
+//before it starts the goroutines of the flow.
+func (j job) start(parent context.Context, p *observer.Policy) (observer.Observer, observer.Subscriber) {
+	o := observer.NewObserver()
+	if j.idle != nil {
+		j.idle.watch(o)
+	}
+	var sub observer.Subscriber
+	if p != nil {
+		sub = o.SubscribeWithPolicy(*p)
+	}
+	ctx, cancel := context.WithCancel(parent)
+	done := make(chan struct{})
+	var stopErr error
+	now := clock.FromContext(parent).Now
+
+	emit := func(v interface{}, err error) bool {
+		if ctx.Err() != nil {
+			return false
+		}
+		if err == nil {
+			err = process(j.nf, o, stamp(v, now))
+		}
+		if p, ok := err.(*PanicError); ok {
+			stopErr = p
+			cancel()
+			return false
+		}
+		if err != nil {
+			switch j.policy {
+			case ForwardErrors:
+				o.NotifyError(err)
+			case StopOnError:
+				stopErr = err
+				cancel()
+				return false
+			}
+		}
+		return true
+	}
+
+	go func() {
+		defer close(done)
+		defer cancel()
+		err := func() (err error) {
+			defer recoverPanic(nil, &err)
+			return j.produce(ctx, emit)
+		}()
+		if stopErr != nil {
+			err = stopErr
+		} else if ctx.Err() != nil {
+			// stopped by Close (nil) or by the parent context
+			err = parent.Err()
+		}
+		o.Finish(err)
+	}()
+
+	control := o.Control()
+	go func() {
+		select {
+		case <-control.C:
+			cancel()
+			<-done
+			control.D <- true
+		case <-parent.Done():
+			// releases a publisher that is blocked by a slow subscriber
+			o.Finish(parent.Err())
+		case <-done:
+		}
+	}()
+
+	return o, sub
+}
+
+//process passes a value through the filter and notifies the observer.
+//It returns the error of the filter if it reports errors
+//or a PanicError if the filter panics.
+func process(nf filters.Filter, o observer.Observer, v interface{}) (err error) {
+	defer recoverPanic(v, &err)
+	if nf.Check(v) {
+		o.Notify(nf.Update(v))
+		return nil
+	}
+	if r, ok := nf.(filters.ErrReporter); ok {
+		return r.Err()
+	}
+	return nil
+}
+
+//stamp sets the time of arrival for events without an event time
+func stamp(v interface{}, now func() time.Time) interface{} {
+	switch e := v.(type) {
+	case filters.Event:
+		if e.Time.IsZero() {
+			e.Time = now()
+			return e
+		}
+	case *filters.Event:
+		if e.Time.IsZero() {
+			e.Time = now()
+		}
+	}
+	return v
+}
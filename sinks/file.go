@@ -0,0 +1,113 @@
+package sinks
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+)
+
+//RotatingFile implements the Sink interface and writes the values to a file
+//in the Format of a Writer. The file is rotated before a value is written if it has
+//reached MaxSize: the file is renamed to Path.1, the previous Path.1 to Path.2 and so on.
+//Only MaxBackups old files are kept. The file is appended to if it exists.
+type RotatingFile struct {
+	Path string
+	//MaxSize is the size in bytes at which the file is rotated (0 is unlimited)
+	MaxSize int64
+	//MaxBackups is the number of rotated files that are kept (0 keeps none)
+	MaxBackups int
+	Format     Format
+	//Header is the first record of every file in the CSV format (optional)
+	Header []string
+	//Comma is the field delimiter of the CSV format (default ',')
+	Comma rune
+	//Perm are the permissions of new files (default 0644)
+	Perm os.FileMode
+	file *os.File
+	w    *Writer
+	size int64
+}
+
+//Write writes the value and rotates the file if it is full
+func (f *RotatingFile) Write(ctx context.Context, v interface{}) error {
+	if f.file != nil && f.MaxSize > 0 && f.size >= f.MaxSize {
+		if err := f.rotate(); err != nil {
+			return err
+		}
+	}
+	if f.file == nil {
+		if err := f.open(); err != nil {
+			return err
+		}
+	}
+	return f.w.Write(ctx, v)
+}
+
+//Close closes the file
+func (f *RotatingFile) Close() error {
+	if f.file == nil {
+		return nil
+	}
+	err := f.file.Close()
+	f.file = nil
+	return err
+}
+
+//open opens the file for appending
+func (f *RotatingFile) open() error {
+	perm := f.Perm
+	if perm == 0 {
+		perm = 0644
+	}
+	file, err := os.OpenFile(f.Path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, perm)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	f.file, f.size = file, info.Size()
+	header := f.Header
+	if f.size > 0 {
+		header = nil
+	}
+	f.w = &Writer{W: &counter{w: file, n: &f.size}, Format: f.Format, Comma: f.Comma, Header: header}
+	return nil
+}
+
+//rotate closes the file and renames the backups
+func (f *RotatingFile) rotate() error {
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if f.MaxBackups <= 0 {
+		return os.Remove(f.Path)
+	}
+	os.Remove(f.backup(f.MaxBackups))
+	for i := f.MaxBackups - 1; i > 0; i-- {
+		if err := os.Rename(f.backup(i), f.backup(i+1)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return os.Rename(f.Path, f.backup(1))
+}
+
+//backup returns the path of the n-th backup
+func (f *RotatingFile) backup(n int) string {
+	return fmt.Sprintf("%s.%d", f.Path, n)
+}
+
+// counter counts the bytes that are written
+type counter struct {
+	w io.Writer
+	n *int64
+}
+
+func (c *counter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	*c.n += int64(n)
+	return n, err
+}
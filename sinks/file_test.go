@@ -0,0 +1,78 @@
+package sinks_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/konimarti/flow/sinks"
+)
+
+func TestRotatingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "values.csv")
+	f := &sinks.RotatingFile{Path: path, MaxSize: 10, MaxBackups: 2, Format: sinks.CSV, Header: []string{"v"}}
+	for _, v := range []float64{1, 2, 3, 4, 5, 6, 7} {
+		if err := f.Write(context.Background(), v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// every file holds the header and 4 values (10 bytes)
+	for file, want := range map[string]string{
+		path:        "v\n5\n6\n7\n",
+		path + ".1": "v\n1\n2\n3\n4\n",
+	} {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != want {
+			t.Errorf("%s: got %q. Expected %q", filepath.Base(file), data, want)
+		}
+	}
+	if _, err := os.Stat(path + ".2"); !os.IsNotExist(err) {
+		t.Errorf("Expected no second backup: %v", err)
+	}
+
+	// the file is appended to and rotated once more
+	f = &sinks.RotatingFile{Path: path, MaxSize: 10, MaxBackups: 2, Format: sinks.CSV, Header: []string{"v"}}
+	for _, v := range []float64{8, 9} {
+		if err := f.Write(context.Background(), v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	f.Close()
+	for file, want := range map[string]string{
+		path:        "v\n9\n",
+		path + ".1": "v\n5\n6\n7\n8\n",
+		path + ".2": "v\n1\n2\n3\n4\n",
+	} {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != want {
+			t.Errorf("%s: got %q. Expected %q", filepath.Base(file), data, want)
+		}
+	}
+}
+
+func TestRotatingFileComma(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "values.csv")
+	f := &sinks.RotatingFile{Path: path, Format: sinks.CSV, Header: []string{"a", "b"}, Comma: ';'}
+	if err := f.Write(context.Background(), []string{"1", "x"}); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "a;b\n1;x\n"; string(data) != want {
+		t.Errorf("Got %q. Expected %q", data, want)
+	}
+}
@@ -0,0 +1,170 @@
+//Package sinks writes the results of a flow to outputs like files, streams and webhooks.
+//
+//A Sink is attached to an observer with Subscribe. It receives the values
+//of the observer in its own goroutine until the flow ends or the subscription is closed:
+//
+//	file, _ := os.Create("results.jsonl")
+//	sub := sinks.Subscribe(yourFlow, &sinks.Writer{W: file, Format: sinks.JSONLines, CloseWriter: true})
+//	go func() {
+//		for err := range sub.Errors() {
+//			log.Println(err)
+//		}
+//	}()
+//	...
+//	err := sub.Close()
+//
+//Use Attach with the subscriber of flow.Subscribe to write all values of a finite source.
+package sinks
+
+import (
+	"context"
+
+	"github.com/konimarti/flow/observer"
+)
+
+//errorBuffer is the size of the buffer of the error channel.
+//Errors are dropped if the buffer is full.
+const errorBuffer = 16
+
+//Sink is the interface for the outputs of a flow.
+//Write is called for every value of the flow and Close is called once when
+//the subscription ends. The methods are not called concurrently.
+//The context of Write is cancelled when the subscription is closed
+//and carries the clock for waits (see clock.FromContext).
+type Sink interface {
+	Write(ctx context.Context, v interface{}) error
+	Close() error
+}
+
+//Func implements the Sink interface with a callback
+type Func func(v interface{}) error
+
+//Write calls the function
+func (f Func) Write(ctx context.Context, v interface{}) error {
+	return f(v)
+}
+
+//Close does nothing
+func (f Func) Close() error {
+	return nil
+}
+
+//Subscription passes the values of an observer to a sink
+type Subscription struct {
+	sink     Sink
+	sub      observer.Subscriber
+	ctx      context.Context
+	cancel   context.CancelFunc
+	errors   chan error
+	done     chan struct{}
+	err      error
+	closeErr error
+}
+
+//Subscribe attaches the sink to the observer.
+//The values are written in a separate goroutine until the flow ends and all values
+//have been written, or until the subscription is closed. The sink is closed afterwards.
+func Subscribe(o observer.Observer, s Sink) *Subscription {
+	return Attach(context.Background(), o.Subscribe(), s)
+}
+
+//SubscribeWithPolicy attaches the sink to the observer with a policy for a slow sink
+//(see observer.Policy)
+func SubscribeWithPolicy(o observer.Observer, s Sink, p observer.Policy) *Subscription {
+	return Attach(context.Background(), o.SubscribeWithPolicy(p), s)
+}
+
+//Attach writes the values of the subscriber to the sink until the stream ends,
+//the subscription is closed or the context is done. The subscriber is closed afterwards.
+func Attach(ctx context.Context, sub observer.Subscriber, s Sink) *Subscription {
+	ctx, cancel := context.WithCancel(ctx)
+	subscription := &Subscription{
+		sink:   s,
+		sub:    sub,
+		ctx:    ctx,
+		cancel: cancel,
+		errors: make(chan error, errorBuffer),
+		done:   make(chan struct{}),
+	}
+	go subscription.run()
+	return subscription
+}
+
+//run writes the values to the sink
+func (s *Subscription) run() {
+	defer close(s.done)
+	defer close(s.errors)
+	defer s.cancel()
+	for {
+		select {
+		case <-s.ctx.Done():
+			s.sub.Close()
+			s.closeErr = s.sink.Close()
+			return
+		default:
+		}
+		select {
+		case <-s.sub.C():
+			if err := s.sink.Write(s.ctx, s.sub.Value()); err != nil {
+				s.report(err)
+			}
+		case <-s.sub.Done():
+			s.err = s.sub.Err()
+			s.closeErr = s.sink.Close()
+			return
+		case <-s.ctx.Done():
+		}
+	}
+}
+
+//report forwards an error to the error channel without blocking
+func (s *Subscription) report(err error) {
+	select {
+	case s.errors <- err:
+	default:
+	}
+}
+
+//Errors returns the channel with the errors of the sink.
+//The channel is closed when the subscription has ended.
+func (s *Subscription) Errors() <-chan error {
+	return s.errors
+}
+
+//Done returns a channel that is closed when the subscription has ended
+//and the sink is closed
+func (s *Subscription) Done() <-chan struct{} {
+	return s.done
+}
+
+//Err returns the error that ended the flow.
+//It returns nil while the subscription is running, if the flow ended normally
+//or if the subscription was closed or its context is done.
+func (s *Subscription) Err() error {
+	select {
+	case <-s.done:
+		return s.err
+	default:
+		return nil
+	}
+}
+
+//Close detaches the sink from the observer. It cancels the context of the current
+//write, e.g. the retries of a Webhook, waits until the write has returned and
+//returns the error of closing the sink.
+//The values that have not been received yet are not written.
+func (s *Subscription) Close() error {
+	s.cancel()
+	<-s.done
+	return s.closeErr
+}
+
+//Wait waits until the flow has ended and all values have been written.
+//It returns the error that ended the flow or the error of closing the sink.
+func (s *Subscription) Wait() error {
+	<-s.done
+	if s.err != nil {
+		return s.err
+	}
+	return s.closeErr
+}
@@ -0,0 +1,124 @@
+package sinks_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/konimarti/flow"
+	"github.com/konimarti/flow/filters"
+	"github.com/konimarti/flow/observer"
+	"github.com/konimarti/flow/sinks"
+)
+
+// recorder is a sink that records the values and fails for negative values
+type recorder struct {
+	mu     sync.Mutex
+	values []interface{}
+	closed bool
+}
+
+func (r *recorder) Write(ctx context.Context, v interface{}) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if f, ok := v.(float64); ok && f < 0 {
+		return errors.New("negative value")
+	}
+	r.values = append(r.values, v)
+	return nil
+}
+
+func (r *recorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.closed = true
+	return nil
+}
+
+func TestSubscribe(t *testing.T) {
+	ch := make(chan interface{})
+	o := flow.New(&filters.None{}, &flow.Chan{Ch: ch})
+	r := &recorder{}
+	sub := sinks.SubscribeWithPolicy(o, r, observer.Policy{MaxLag: 1, Overflow: observer.Block})
+
+	for _, v := range []float64{1, -1, 2, 3} {
+		ch <- v
+	}
+	close(ch)
+	if err := sub.Wait(); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(r.values) != 3 || r.values[2] != 3.0 || !r.closed {
+		t.Errorf("Got %v (closed %v). Expected [1 2 3] and a closed sink", r.values, r.closed)
+	}
+	var errs []error
+	for err := range sub.Errors() {
+		errs = append(errs, err)
+	}
+	if len(errs) != 1 || errs[0].Error() != "negative value" {
+		t.Errorf("Got errors %v. Expected negative value", errs)
+	}
+}
+
+func TestSubscriptionClose(t *testing.T) {
+	ch := make(chan interface{})
+	o := flow.New(&filters.None{}, &flow.Chan{Ch: ch})
+	defer o.Close()
+
+	received := make(chan interface{}, 1)
+	sub := sinks.Subscribe(o, sinks.Func(func(v interface{}) error {
+		received <- v
+		return nil
+	}))
+	ch <- 1.0
+	<-received
+
+	if err := sub.Close(); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-sub.Done():
+	default:
+		t.Error("Expected subscription to be done")
+	}
+	if n := o.Subscribers(); n != 0 {
+		t.Errorf("Got %d subscribers. Expected 0", n)
+	}
+	ch <- 2.0
+	select {
+	case v := <-received:
+		t.Errorf("Got %v after Close", v)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestSubscriptionErr(t *testing.T) {
+	want := errors.New("broken")
+	o := flow.New(filters.NewErrChain(filters.NewErrFilter(&filters.None{})), &flow.FuncErr{
+		Fn:      func() (interface{}, error) { return nil, want },
+		Refresh: time.Millisecond,
+		Policy:  flow.StopOnError,
+	})
+	sub := sinks.Subscribe(o, sinks.Func(func(v interface{}) error { return nil }))
+	if err := sub.Wait(); err != want {
+		t.Errorf("Got %v. Expected %v", err, want)
+	}
+	if err := sub.Err(); err != want {
+		t.Errorf("Got %v. Expected %v", err, want)
+	}
+}
+
+func TestAttach(t *testing.T) {
+	_, subscriber := flow.Subscribe(&filters.None{}, &flow.Reader{R: strings.NewReader("1\n2\n3\n"), Decoder: flow.FloatDecoder})
+	r := &recorder{}
+	if err := sinks.Attach(context.Background(), subscriber, r).Wait(); err != nil {
+		t.Fatal(err)
+	}
+	if len(r.values) != 3 || !r.closed {
+		t.Errorf("Got %v (closed %v). Expected [1 2 3] and a closed sink", r.values, r.closed)
+	}
+}
@@ -0,0 +1,106 @@
+package sinks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/konimarti/flow/clock"
+)
+
+//Webhook implements the Sink interface and sends every value as a JSON object
+//(see JSONLines) in the body of an HTTP request.
+//Failed requests are retried with an exponential backoff if the server cannot be reached
+//or responds with 429 or a 5xx status. The retries are cancelled with the context of Write,
+//i.e. when the subscription is closed, and wait with the clock of the context.
+type Webhook struct {
+	URL string
+	//Method is the HTTP method (default POST)
+	Method string
+	Header http.Header
+	//Timeout is the timeout of a request (default 10s)
+	Timeout time.Duration
+	//MaxAttempts is the maximal number of requests per value (default 3)
+	MaxAttempts int
+	//Backoff is the wait before the first retry and doubles with every retry (default 1s)
+	Backoff time.Duration
+	Client  *http.Client
+}
+
+//Write sends the value
+func (w *Webhook) Write(ctx context.Context, v interface{}) error {
+	body, err := json.Marshal(newRecord(v))
+	if err != nil {
+		return err
+	}
+	attempts := w.MaxAttempts
+	if attempts <= 0 {
+		attempts = 3
+	}
+	backoff := w.Backoff
+	if backoff <= 0 {
+		backoff = time.Second
+	}
+	for attempt := 1; ; attempt++ {
+		retry, err := w.send(ctx, body)
+		if err == nil || !retry || attempt == attempts {
+			return err
+		}
+		select {
+		case <-clock.FromContext(ctx).After(backoff):
+		case <-ctx.Done():
+			return err
+		}
+		backoff *= 2
+	}
+}
+
+//send sends a request and returns if a failed request can be retried.
+//Requests that time out are retried unless the context of Write is done.
+func (w *Webhook) send(parent context.Context, body []byte) (bool, error) {
+	timeout := w.Timeout
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	ctx, cancel := context.WithTimeout(parent, timeout)
+	defer cancel()
+
+	method := w.Method
+	if method == "" {
+		method = http.MethodPost
+	}
+	req, err := http.NewRequestWithContext(ctx, method, w.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	for key, values := range w.Header {
+		req.Header[key] = values
+	}
+	if req.Header.Get("Content-Type") == "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	client := w.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return parent.Err() == nil, err
+	}
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		retry := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+		return retry, fmt.Errorf("sinks: %s %s: %s", method, w.URL, resp.Status)
+	}
+	return false, nil
+}
+
+//Close does nothing
+func (w *Webhook) Close() error {
+	return nil
+}
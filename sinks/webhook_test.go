@@ -0,0 +1,130 @@
+package sinks_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/konimarti/flow"
+	"github.com/konimarti/flow/clock"
+	"github.com/konimarti/flow/filters"
+	"github.com/konimarti/flow/sinks"
+)
+
+func TestWebhook(t *testing.T) {
+	var mu sync.Mutex
+	var bodies []string
+	failures, requests := 2, 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		requests++
+		if r.Header.Get("Authorization") != "token" || r.Header.Get("Content-Type") != "application/json" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if failures > 0 {
+			failures--
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+	}))
+	defer srv.Close()
+
+	w := &sinks.Webhook{URL: srv.URL, Header: http.Header{"Authorization": {"token"}}, Backoff: time.Millisecond}
+	if err := w.Write(context.Background(), 1.0); err != nil {
+		t.Fatal(err)
+	}
+	if len(bodies) != 1 || bodies[0] != `{"value":1}` {
+		t.Errorf("Got %v. Expected one request after two retries", bodies)
+	}
+
+	failures = 3
+	if err := w.Write(context.Background(), 2.0); err == nil {
+		t.Error("Expected error after three attempts")
+	}
+
+	w.Header = nil
+	requests = 0
+	if err := w.Write(context.Background(), 3.0); err == nil {
+		t.Error("Expected error for 401")
+	}
+	if requests != 1 {
+		t.Errorf("Got %d requests. Expected no retry for 401", requests)
+	}
+}
+
+func TestWebhookTimeout(t *testing.T) {
+	var mu sync.Mutex
+	requests := 0
+	release := make(chan bool)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests++
+		first := requests == 1
+		mu.Unlock()
+		if first {
+			<-release
+		}
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	// the request that timed out is retried
+	w := &sinks.Webhook{URL: srv.URL, Timeout: 50 * time.Millisecond, Backoff: time.Millisecond}
+	if err := w.Write(context.Background(), 1.0); err != nil {
+		t.Fatal(err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if requests != 2 {
+		t.Errorf("Got %d requests. Expected a retry after the timeout", requests)
+	}
+}
+
+func TestWebhookClose(t *testing.T) {
+	var mu sync.Mutex
+	requests := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests++
+		mu.Unlock()
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	c := clock.NewManual(time.Now())
+	ctx := clock.NewContext(context.Background(), c)
+	ch := make(chan interface{})
+	o := flow.New(&filters.None{}, &flow.Chan{Ch: ch})
+	defer o.Close()
+	sub := sinks.Attach(ctx, o.Subscribe(), &sinks.Webhook{URL: srv.URL, MaxAttempts: 5, Backoff: time.Minute})
+	ch <- 1.0
+
+	// the first retry is sent after the backoff of the clock
+	c.BlockUntil(1)
+	c.Advance(time.Minute)
+	c.BlockUntil(1)
+
+	closed := make(chan error, 1)
+	go func() { closed <- sub.Close() }()
+	select {
+	case <-closed:
+	case <-time.After(time.Second):
+		t.Fatal("Close should cancel the retries")
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if requests != 2 {
+		t.Errorf("Got %d requests. Expected 2", requests)
+	}
+	if err := <-sub.Errors(); err == nil {
+		t.Error("Expected the error of the cancelled write")
+	}
+}
@@ -0,0 +1,151 @@
+package sinks
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/konimarti/flow/filters"
+)
+
+//Format is the format of the values in a Writer
+type Format int
+
+const (
+	//Text writes a value per line. Events are written with their time and key.
+	Text Format = iota
+	//JSONLines writes a JSON object per line with the fields time, key and meta
+	//of an Event and the value
+	JSONLines
+	//CSV writes a record per value with the time and the key of an Event
+	//followed by the value. Slices are written as multiple fields.
+	CSV
+)
+
+//Writer implements the Sink interface and writes the values to an io.Writer.
+type Writer struct {
+	W      io.Writer
+	Format Format
+	//CloseWriter closes W with Close if it is an io.Closer, e.g. a file
+	CloseWriter bool
+	//Header is the first record of the CSV format (optional)
+	Header []string
+	//Comma is the field delimiter of the CSV format (default ',')
+	Comma rune
+	csv   *csv.Writer
+	enc   *json.Encoder
+}
+
+//Write formats the value and writes it
+func (w *Writer) Write(ctx context.Context, v interface{}) error {
+	switch w.Format {
+	case JSONLines:
+		if w.enc == nil {
+			w.enc = json.NewEncoder(w.W)
+		}
+		return w.enc.Encode(newRecord(v))
+	case CSV:
+		if w.csv == nil {
+			w.csv = csv.NewWriter(w.W)
+			if w.Comma != 0 {
+				w.csv.Comma = w.Comma
+			}
+			if len(w.Header) > 0 {
+				w.csv.Write(w.Header)
+			}
+		}
+		w.csv.Write(csvFields(v))
+		w.csv.Flush()
+		return w.csv.Error()
+	}
+	_, err := fmt.Fprintln(w.W, text(v))
+	return err
+}
+
+//Close closes the io.Writer if CloseWriter is set and it is an io.Closer
+func (w *Writer) Close() error {
+	if c, ok := w.W.(io.Closer); ok && w.CloseWriter {
+		return c.Close()
+	}
+	return nil
+}
+
+//event returns the value as an Event
+func event(v interface{}) (filters.Event, bool) {
+	switch e := v.(type) {
+	case filters.Event:
+		return e, true
+	case *filters.Event:
+		return *e, true
+	}
+	return filters.Event{}, false
+}
+
+//formatTime formats the time of an Event or returns "" for the zero time
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339Nano)
+}
+
+//text returns the line of a value in the Text format
+func text(v interface{}) string {
+	e, ok := event(v)
+	if !ok {
+		return fmt.Sprint(v)
+	}
+	var fields []string
+	if t := formatTime(e.Time); t != "" {
+		fields = append(fields, t)
+	}
+	if e.Key != "" {
+		fields = append(fields, e.Key)
+	}
+	return strings.Join(append(fields, fmt.Sprint(e.Value)), " ")
+}
+
+// record is a value in the JSONLines format
+type record struct {
+	Time  string            `json:"time,omitempty"`
+	Key   string            `json:"key,omitempty"`
+	Meta  map[string]string `json:"meta,omitempty"`
+	Value interface{}       `json:"value"`
+}
+
+func newRecord(v interface{}) record {
+	e, ok := event(v)
+	if !ok {
+		return record{Value: v}
+	}
+	return record{Time: formatTime(e.Time), Key: e.Key, Meta: e.Meta, Value: e.Value}
+}
+
+//csvFields returns the fields of a value in the CSV format
+func csvFields(v interface{}) []string {
+	var fields []string
+	e, isEvent := event(v)
+	if isEvent {
+		fields = append(fields, formatTime(e.Time), e.Key)
+		v = e.Value
+	}
+	switch values := v.(type) {
+	case []interface{}:
+		for _, value := range values {
+			fields = append(fields, fmt.Sprint(value))
+		}
+	case []float64:
+		for _, value := range values {
+			fields = append(fields, fmt.Sprint(value))
+		}
+	case []string:
+		fields = append(fields, values...)
+	default:
+		fields = append(fields, fmt.Sprint(v))
+	}
+	return fields
+}
@@ -0,0 +1,72 @@
+package sinks_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/konimarti/flow/filters"
+	"github.com/konimarti/flow/sinks"
+)
+
+func TestWriter(t *testing.T) {
+	e := filters.Event{
+		Time:  time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		Key:   "cpu",
+		Meta:  map[string]string{"host": "a"},
+		Value: 0.5,
+	}
+	tests := []struct {
+		w    sinks.Writer
+		want string
+	}{
+		{sinks.Writer{}, "1\n2024-01-02T03:04:05Z cpu 0.5\n[1 x]\n"},
+		{sinks.Writer{Format: sinks.JSONLines},
+			`{"value":1}` + "\n" +
+				`{"time":"2024-01-02T03:04:05Z","key":"cpu","meta":{"host":"a"},"value":0.5}` + "\n" +
+				`{"value":[1,"x"]}` + "\n"},
+		{sinks.Writer{Format: sinks.CSV, Header: []string{"a", "b"}, Comma: ';'},
+			"a;b\n1\n2024-01-02T03:04:05Z;cpu;0.5\n1;x\n"},
+	}
+	for _, test := range tests {
+		var b bytes.Buffer
+		w := test.w
+		w.W = &b
+		for _, v := range []interface{}{1.0, &e, []interface{}{1, "x"}} {
+			if err := w.Write(context.Background(), v); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+		if b.String() != test.want {
+			t.Errorf("Format %d: got %q. Expected %q", test.w.Format, b.String(), test.want)
+		}
+	}
+}
+
+// closer records if it was closed
+type closer struct {
+	bytes.Buffer
+	closed bool
+}
+
+func (c *closer) Close() error {
+	c.closed = true
+	return nil
+}
+
+func TestWriterClose(t *testing.T) {
+	for _, closeWriter := range []bool{false, true} {
+		c := &closer{}
+		w := &sinks.Writer{W: c, CloseWriter: closeWriter}
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+		if c.closed != closeWriter {
+			t.Errorf("Got closed %v. Expected %v", c.closed, closeWriter)
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package flow
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"time"
+
+	"github.com/konimarti/flow/clock"
+	"github.com/konimarti/flow/filters"
+	"github.com/konimarti/flow/observer"
+)
+
+//PanicError is the error that ends a flow when a source or a filter panics
+type PanicError struct {
+	//Value is the value that was processed when the filter panicked.
+	//It is nil if the source panicked.
+	Value interface{}
+	//Recovered is the value returned by recover
+	Recovered interface{}
+	//Stack is the stack trace of the panicking goroutine
+	Stack []byte
+}
+
+func (p *PanicError) Error() string {
+	if p.Value == nil {
+		return fmt.Sprintf("flow: panic in source: %v", p.Recovered)
+	}
+	return fmt.Sprintf("flow: panic while processing %v: %v", p.Value, p.Recovered)
+}
+
+//Supervisor implements the Source interface and supervises another source.
+//It reports panics of the source and its filters and optionally restarts
+//the flow with an exponential backoff. The backoff doubles with every
+//consecutive panic and is reset when the flow has run longer than MaxBackoff.
+type Supervisor struct {
+	Source Source
+	//OnPanic is called for every panic
+	OnPanic func(*PanicError)
+	//Restart restarts the flow after a panic. If false, the flow ends with the PanicError.
+	Restart bool
+	//Backoff is the initial delay before a restart (default 100ms)
+	Backoff time.Duration
+	//MaxBackoff is the maximal delay before a restart (default 1 minute)
+	MaxBackoff time.Duration
+}
+
+//Run starts the supervised flow
+func (s *Supervisor) Run(nf filters.Filter) observer.Observer {
+	return s.RunContext(context.Background(), nf)
+}
+
+//RunContext starts the supervised flow until the context is done
+func (s *Supervisor) RunContext(ctx context.Context, nf filters.Filter) observer.Observer {
+	return s.job(nf).run(ctx)
+}
+
+//job returns the flow that supervises the source
+func (s *Supervisor) job(nf filters.Filter) job {
+	return job{nf: &filters.None{}, policy: ForwardErrors, produce: func(ctx context.Context, emit emitFunc) error {
+		backoff, maxBackoff := s.backoff()
+		delay := backoff
+		c := clock.FromContext(ctx)
+		for {
+			started := c.Now()
+			// the subscriber is attached before the source starts, so that no values are lost
+			o, sub := SubscribeWithContext(ctx, nf, s.Source, observer.Policy{})
+			err := forward(ctx, o, sub, emit)
+			p, ok := err.(*PanicError)
+			if !ok {
+				return err
+			}
+			if s.OnPanic != nil {
+				s.OnPanic(p)
+			}
+			if !s.Restart {
+				return p
+			}
+			if c.Now().Sub(started) > maxBackoff {
+				delay = backoff
+			}
+			select {
+			case <-c.After(delay):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+			if delay *= 2; delay > maxBackoff {
+				delay = maxBackoff
+			}
+		}
+	}}
+}
+
+func (s *Supervisor) backoff() (time.Duration, time.Duration) {
+	backoff, maxBackoff := s.Backoff, s.MaxBackoff
+	if backoff <= 0 {
+		backoff = 100 * time.Millisecond
+	}
+	if maxBackoff <= 0 {
+		maxBackoff = time.Minute
+	}
+	if maxBackoff < backoff {
+		maxBackoff = backoff
+	}
+	return backoff, maxBackoff
+}
+
+//forward emits the values of a subscriber and the errors of its observer until
+//the observer has finished. It returns the error that ended the observer.
+func forward(ctx context.Context, o observer.Observer, sub observer.Subscriber, emit emitFunc) error {
+	defer o.Close()
+	errs := o.Errors()
+	for {
+		select {
+		case <-sub.C():
+			if !emit(sub.Value(), nil) {
+				return nil
+			}
+		case err, ok := <-errs:
+			if !ok {
+				errs = nil
+				continue
+			}
+			if !emit(nil, err) {
+				return nil
+			}
+		case <-sub.Done():
+			return sub.Err()
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+//recoverPanic recovers a panic and stores it as PanicError in err
+func recoverPanic(value interface{}, err *error) {
+	if r := recover(); r != nil {
+		*err = &PanicError{Value: value, Recovered: r, Stack: debug.Stack()}
+	}
+}
@@ -0,0 +1,123 @@
+package flow_test
+
+import (
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/konimarti/flow"
+	"github.com/konimarti/flow/filters"
+)
+
+func TestPanicEndsFlow(t *testing.T) {
+	ch := make(chan interface{})
+	observer := flow.New(&filters.BelowFloat64{Value: 1.0}, &flow.Chan{Ch: ch})
+	defer observer.Close()
+	subscriber := observer.Subscribe()
+
+	go func() { ch <- 1 }()
+
+	select {
+	case <-time.After(1 * time.Second):
+		t.Fatal("subscriber should be closed after a panic")
+	case <-subscriber.Done():
+		p, ok := subscriber.Err().(*flow.PanicError)
+		if !ok {
+			t.Fatalf("Got %v. Expected a PanicError", subscriber.Err())
+		}
+		if p.Value != 1 {
+			t.Errorf("Got value %v. Expected 1", p.Value)
+		}
+	}
+}
+
+func TestSupervisorRestart(t *testing.T) {
+	ch := make(chan interface{})
+	var mu sync.Mutex
+	var panics []interface{}
+
+	observer := flow.New(&filters.BelowFloat64{Value: 10.0}, &flow.Supervisor{
+		Source: &flow.Chan{Ch: ch},
+		OnPanic: func(p *flow.PanicError) {
+			mu.Lock()
+			panics = append(panics, p.Value)
+			mu.Unlock()
+		},
+		Restart:    true,
+		Backoff:    time.Millisecond,
+		MaxBackoff: 10 * time.Millisecond,
+	})
+	defer observer.Close()
+	subscriber := observer.Subscribe()
+
+	go func() {
+		for _, v := range []interface{}{1.0, "boom", 2, 3.0} {
+			ch <- v
+		}
+	}()
+
+	for _, want := range []float64{1.0, 3.0} {
+		select {
+		case <-time.After(1 * time.Second):
+			t.Fatal("timed out")
+		case <-subscriber.C():
+			if v := subscriber.Value(); v != want {
+				t.Errorf("Got %v. Expected %v", v, want)
+			}
+		case <-subscriber.Done():
+			t.Fatalf("supervised flow should not end: %v", subscriber.Err())
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(panics) != 2 || panics[0] != "boom" || panics[1] != 2 {
+		t.Errorf("Got panics %v. Expected [boom 2]", panics)
+	}
+}
+
+func TestSupervisorWithoutRestart(t *testing.T) {
+	var i int
+	observer := flow.New(&filters.None{}, &flow.Supervisor{
+		Source: &flow.Func{
+			Fn: func() interface{} {
+				if i++; i == 2 {
+					panic("source failed")
+				}
+				return i
+			},
+			Refresh: 10 * time.Millisecond,
+		},
+	})
+	defer observer.Close()
+	subscriber := observer.Subscribe()
+
+	for {
+		select {
+		case <-time.After(1 * time.Second):
+			t.Fatal("timed out")
+		case <-subscriber.C():
+			subscriber.Value()
+			continue
+		case <-subscriber.Done():
+			if _, ok := subscriber.Err().(*flow.PanicError); !ok {
+				t.Errorf("Got %v. Expected a PanicError", subscriber.Err())
+			}
+		}
+		return
+	}
+}
+
+func TestSupervisorFiniteSource(t *testing.T) {
+	for i := 0; i < 50; i++ {
+		_, subscriber := flow.Subscribe(&filters.None{}, &flow.Supervisor{
+			Source: &flow.Reader{R: strings.NewReader("1\n2\n3\n"), Decoder: flow.FloatDecoder},
+		})
+		runtime.Gosched()
+		if values := collect(t, subscriber); len(values) != 3 {
+			t.Fatalf("Got %v. Expected [1 2 3]", values)
+		}
+	}
+}
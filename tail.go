@@ -0,0 +1,206 @@
+package flow
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"github.com/konimarti/flow/clock"
+	"github.com/konimarti/flow/filters"
+	"github.com/konimarti/flow/observer"
+)
+
+//Tail implements the Source interface and follows a file like tail -F.
+//Every appended line is decoded by the Decoder (default StringDecoder)
+//and passed to the filters. Tail survives truncation and rename-based rotation
+//of the file and waits for the file if it does not exist.
+//The file is polled every Poll (default 250ms).
+//Decoding errors are handled according to the Policy.
+type Tail struct {
+	Path string
+	//FromEnd starts at the end of the file instead of the Offset
+	FromEnd bool
+	//Offset is the position in the file to start from, e.g. a saved offset from Pos
+	Offset  int64
+	Poll    time.Duration
+	Decoder Decoder
+	Policy  ErrorPolicy
+	pos     int64
+}
+
+//Pos returns the offset in the current file after the last line that was read.
+//It can be saved to resume with Offset.
+func (t *Tail) Pos() int64 {
+	return atomic.LoadInt64(&t.pos)
+}
+
+//Run follows the file and passes the decoded lines to the filters
+func (t *Tail) Run(nf filters.Filter) observer.Observer {
+	return t.RunContext(context.Background(), nf)
+}
+
+//RunContext follows the file and passes the decoded lines to the filters until the context is done
+func (t *Tail) RunContext(ctx context.Context, nf filters.Filter) observer.Observer {
+	return t.job(nf).run(ctx)
+}
+
+//job returns the flow that follows the file and passes the decoded lines to the filters
+func (t *Tail) job(nf filters.Filter) job {
+	decoder := decoderOr(t.Decoder)
+	poll := t.Poll
+	if poll <= 0 {
+		poll = 250 * time.Millisecond
+	}
+	return job{nf: nf, policy: t.Policy, produce: func(ctx context.Context, emit emitFunc) error {
+		f := &follower{path: t.Path, pos: &t.pos}
+		defer f.close()
+		first := true
+		c := clock.FromContext(ctx)
+		for {
+			ok, err := f.poll(first, t.FromEnd, t.Offset, func(line []byte) bool {
+				return emit(decoder.Decode(line))
+			})
+			if err != nil {
+				return err
+			}
+			if !ok {
+				return nil
+			}
+			// a file that is created later is read from the beginning
+			first = false
+			// the next poll is scheduled after the file has been read,
+			// so a manual clock can wait for it with BlockUntil
+			select {
+			case <-c.After(poll):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+	}}
+}
+
+//follower keeps track of the followed file
+type follower struct {
+	path    string
+	file    *os.File
+	offset  int64
+	partial []byte
+	pos     *int64
+}
+
+//poll reads all new lines of the file.
+//It returns false if fn returned false.
+func (f *follower) poll(first, fromEnd bool, offset int64, fn func([]byte) bool) (bool, error) {
+	if f.file == nil {
+		if err := f.open(first, fromEnd, offset); err != nil {
+			if os.IsNotExist(err) {
+				return true, nil
+			}
+			return false, err
+		}
+	}
+
+	info, err := f.file.Stat()
+	if err != nil {
+		return false, err
+	}
+	if info.Size() < f.offset {
+		// truncated: start over
+		if err := f.seek(0); err != nil {
+			return false, err
+		}
+	}
+	if ok, err := f.read(fn); !ok || err != nil {
+		return ok, err
+	}
+
+	// rotated: the path points to a new file
+	latest, err := os.Stat(f.path)
+	if err == nil && !os.SameFile(latest, info) {
+		f.close()
+		if err := f.open(false, false, 0); err != nil && !os.IsNotExist(err) {
+			return false, err
+		}
+		if f.file != nil {
+			return f.read(fn)
+		}
+	}
+	return true, nil
+}
+
+func (f *follower) open(first, fromEnd bool, offset int64) error {
+	file, err := os.Open(f.path)
+	if err != nil {
+		return err
+	}
+	f.file = file
+	switch {
+	case first && fromEnd:
+		offset, err = file.Seek(0, io.SeekEnd)
+		if err != nil {
+			return err
+		}
+		f.offset = offset
+		f.setPos()
+		return nil
+	case first:
+		return f.seek(offset)
+	}
+	return f.seek(0)
+}
+
+func (f *follower) seek(offset int64) error {
+	if _, err := f.file.Seek(offset, io.SeekStart); err != nil {
+		return err
+	}
+	f.offset = offset
+	f.partial = f.partial[:0]
+	f.setPos()
+	return nil
+}
+
+//read passes the complete lines that were appended to fn
+func (f *follower) read(fn func([]byte) bool) (bool, error) {
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := f.file.Read(buf)
+		data := buf[:n]
+		for len(data) > 0 {
+			i := bytes.IndexByte(data, '\n')
+			if i < 0 {
+				f.partial = append(f.partial, data...)
+				break
+			}
+			line := append(f.partial, data[:i]...)
+			f.offset += int64(len(line) + 1)
+			f.partial = nil
+			data = data[i+1:]
+			f.setPos()
+			line = bytes.TrimSuffix(line, []byte("\r"))
+			if len(line) > 0 && !fn(line) {
+				return false, nil
+			}
+		}
+		if err == io.EOF || n == 0 {
+			return true, nil
+		}
+		if err != nil {
+			return false, err
+		}
+	}
+}
+
+func (f *follower) setPos() {
+	atomic.StoreInt64(f.pos, f.offset)
+}
+
+func (f *follower) close() {
+	if f.file != nil {
+		f.file.Close()
+		f.file = nil
+	}
+	f.partial = nil
+}
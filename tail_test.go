@@ -0,0 +1,119 @@
+package flow_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/konimarti/flow"
+	"github.com/konimarti/flow/clock"
+	"github.com/konimarti/flow/filters"
+	"github.com/konimarti/flow/observer"
+)
+
+func appendFile(t *testing.T, path, data string) {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if _, err := f.WriteString(data); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func expect(t *testing.T, subscriber observer.Subscriber, want ...interface{}) {
+	for _, w := range want {
+		select {
+		case <-time.After(2 * time.Second):
+			t.Fatalf("timed out waiting for %v", w)
+		case <-subscriber.C():
+			if v := subscriber.Value(); v != w {
+				t.Fatalf("Got %v. Expected %v", v, w)
+			}
+		}
+	}
+}
+
+func TestTail(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	appendFile(t, path, "old\n")
+
+	c := clock.NewManual(time.Now())
+	poll := time.Second
+	source := &flow.Tail{Path: path, FromEnd: true, Poll: poll}
+	observer, subscriber := flow.SubscribeWithContext(clock.NewContext(context.Background(), c), &filters.None{}, source, observer.Policy{})
+	defer observer.Close()
+
+	// the tail waits for the next poll after it has read the file
+	next := func() {
+		c.BlockUntil(1)
+		c.Advance(poll)
+	}
+
+	// the file is opened at the end
+	c.BlockUntil(1)
+	if pos := source.Pos(); pos != 4 {
+		t.Fatalf("Got position %d. Expected 4", pos)
+	}
+
+	appendFile(t, path, "first\nsec")
+	next()
+	expect(t, subscriber, "first")
+	appendFile(t, path, "ond\n")
+	next()
+	expect(t, subscriber, "second")
+
+	// truncation
+	if err := os.Truncate(path, 0); err != nil {
+		t.Fatal(err)
+	}
+	next()
+	c.BlockUntil(1)
+	if pos := source.Pos(); pos != 0 {
+		t.Fatalf("Got position %d. Expected 0 after truncation", pos)
+	}
+	appendFile(t, path, "after truncate\n")
+	next()
+	expect(t, subscriber, "after truncate")
+
+	// rotation: the remaining lines of the old file are read
+	appendFile(t, path, "before rotate\n")
+	if err := os.Rename(path, path+".1"); err != nil {
+		t.Fatal(err)
+	}
+	appendFile(t, path, "after rotate\n")
+	next()
+	expect(t, subscriber, "before rotate", "after rotate")
+}
+
+func TestTailOffset(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	appendFile(t, path, "1\n2\n3\n")
+
+	source := &flow.Tail{Path: path, Offset: 2, Poll: 5 * time.Millisecond, Decoder: flow.FloatDecoder}
+	observer, subscriber := flow.Subscribe(&filters.None{}, source)
+	defer observer.Close()
+
+	expect(t, subscriber, 2.0, 3.0)
+	if pos := source.Pos(); pos != 6 {
+		t.Errorf("Got position %d. Expected 6", pos)
+	}
+}
+
+func TestTailWaitsForFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+
+	c := clock.NewManual(time.Now())
+	poll := time.Second
+	observer, subscriber := flow.SubscribeWithContext(clock.NewContext(context.Background(), c), &filters.None{}, &flow.Tail{Path: path, Poll: poll}, observer.Policy{})
+	defer observer.Close()
+
+	// the file does not exist at the first poll
+	c.BlockUntil(1)
+	appendFile(t, path, "created\n")
+	c.Advance(poll)
+	expect(t, subscriber, "created")
+}
@@ -0,0 +1,208 @@
+//Package typed provides a type-safe, generic counterpart of the
+//flow, filters and observer packages. Pipelines built with this package
+//are checked at compile time and interoperate with the interface{}-based
+//types through adapters.
+package typed
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/konimarti/flow/filters"
+)
+
+// Filter defines the generic interface that
+// filters or processes incoming data of type In
+// and decides when to notify the observers with a value of type Out.
+type Filter[In, Out any] interface {
+	//Check should return true if the observers should be notified.
+	Check(In) bool
+	//Update processes the new value. Its return value is sent to the observers
+	//and it is only called when Check returns true.
+	Update(In) Out
+}
+
+// Model struct implements the generic Filter interface.
+// It forwards all data unfiltered and unprocessed.
+// Model can be embedded in structs to write user-defined filters.
+type Model[T any] struct {
+}
+
+//Check always returns true.
+func (m *Model[T]) Check(newValue T) bool { return true }
+
+//Update returns the current value that is sent to the observers.
+func (m *Model[T]) Update(newValue T) T {
+	return newValue
+}
+
+//FilterFunc implements the generic Filter interface with two functions.
+//A nil CheckFn always returns true.
+type FilterFunc[In, Out any] struct {
+	CheckFn  func(In) bool
+	UpdateFn func(In) Out
+}
+
+//Check calls CheckFn.
+func (f *FilterFunc[In, Out]) Check(newValue In) bool {
+	if f.CheckFn == nil {
+		return true
+	}
+	return f.CheckFn(newValue)
+}
+
+//Update calls UpdateFn.
+func (f *FilterFunc[In, Out]) Update(newValue In) Out {
+	return f.UpdateFn(newValue)
+}
+
+//Untyped returns a filters.Filter for a generic filter.
+//Values that are not of type In are rejected by Check
+//instead of causing a panic.
+func Untyped[In, Out any](f Filter[In, Out]) TypedFilter[In] {
+	return &untyped[In, Out]{f: f}
+}
+
+type untyped[In, Out any] struct {
+	f Filter[In, Out]
+}
+
+func (u *untyped[In, Out]) Check(v interface{}) bool {
+	in, ok := v.(In)
+	if !ok {
+		return false
+	}
+	return u.f.Check(in)
+}
+
+func (u *untyped[In, Out]) Update(v interface{}) interface{} {
+	in, ok := v.(In)
+	if !ok {
+		return nil
+	}
+	return u.f.Update(in)
+}
+
+//Err returns the error of the generic filter (see filters.ErrReporter)
+func (u *untyped[In, Out]) Err() error {
+	return errOf(u.f)
+}
+
+//errOf returns the error of a filter that implements filters.ErrReporter
+func errOf(f interface{}) error {
+	if r, ok := f.(filters.ErrReporter); ok {
+		return r.Err()
+	}
+	return nil
+}
+
+//Typed returns a generic filter for a filters.Filter.
+//Check calls the Update of the filter to get the result.
+//If the result is not of type Out, Check is false for that value
+//and the mismatch is reported by Err (see filters.ErrReporter).
+func Typed[In, Out any](f filters.Filter) Filter[In, Out] {
+	return &typedFilter[In, Out]{f: f}
+}
+
+type typedFilter[In, Out any] struct {
+	f     filters.Filter
+	value Out
+	err   error
+}
+
+func (t *typedFilter[In, Out]) Check(v In) bool {
+	t.err = nil
+	if !t.f.Check(v) {
+		t.err = errOf(t.f)
+		return false
+	}
+	result := t.f.Update(v)
+	out, ok := result.(Out)
+	if !ok {
+		t.err = fmt.Errorf("typed: %T returned %v of type %T, not %v", t.f, result, result, reflect.TypeOf((*Out)(nil)).Elem())
+		return false
+	}
+	t.value = out
+	return true
+}
+
+//Err returns the error of the filter or of a result that is not of type Out
+func (t *typedFilter[In, Out]) Err() error {
+	return t.err
+}
+
+func (t *typedFilter[In, Out]) Update(v In) Out {
+	return t.value
+}
+
+//NewChain chains together generic filters of the same type.
+func NewChain[T any](fs ...Filter[T, T]) Filter[T, T] {
+	chained := make([]Filter[T, T], 0, len(fs))
+	chained = append(chained, fs...)
+	return &chain[T]{fs: chained}
+}
+
+type chain[T any] struct {
+	fs    []Filter[T, T]
+	value T
+	err   error
+}
+
+func (c *chain[T]) Check(v T) bool {
+	c.value = v
+	c.err = nil
+	for _, f := range c.fs {
+		if !f.Check(c.value) {
+			c.err = errOf(f)
+			return false
+		}
+		c.value = f.Update(c.value)
+	}
+	return true
+}
+
+func (c *chain[T]) Update(v T) T {
+	return c.value
+}
+
+//Err returns the error of the filter that rejected the value
+func (c *chain[T]) Err() error {
+	return c.err
+}
+
+//Then connects two generic filters of different types.
+//The second filter only receives values for which the first filter's Check is true.
+func Then[A, B, C any](first Filter[A, B], second Filter[B, C]) Filter[A, C] {
+	return &then[A, B, C]{first: first, second: second}
+}
+
+type then[A, B, C any] struct {
+	first  Filter[A, B]
+	second Filter[B, C]
+	value  C
+	err    error
+}
+
+func (t *then[A, B, C]) Check(v A) bool {
+	t.err = nil
+	if !t.first.Check(v) {
+		t.err = errOf(t.first)
+		return false
+	}
+	b := t.first.Update(v)
+	if !t.second.Check(b) {
+		t.err = errOf(t.second)
+		return false
+	}
+	t.value = t.second.Update(b)
+	return true
+}
+
+func (t *then[A, B, C]) Update(v A) C {
+	return t.value
+}
+
+//Err returns the error of the filter that rejected the value
+func (t *then[A, B, C]) Err() error {
+	return t.err
+}
@@ -0,0 +1,157 @@
+package typed
+
+import (
+	"github.com/konimarti/flow/observer"
+)
+
+//Observer is the generic counterpart of observer.Observer.
+type Observer[T any] interface {
+	Notify(T)
+	Subscribe() Subscriber[T]
+	SubscribeWithPolicy(observer.Policy) Subscriber[T]
+	SubscribeWithReplay(n int) Subscriber[T]
+	Replay(n int)
+	Latest() (T, bool)
+	Unsubscribe(Subscriber[T])
+	Subscribers() int
+	OnIdle(func())
+	Close()
+	//Errors returns the channel with the forwarded errors (see observer.Observer).
+	Errors() <-chan error
+	//Untyped returns the underlying observer.Observer.
+	Untyped() observer.Observer
+}
+
+//Subscriber is the generic counterpart of observer.Subscriber.
+type Subscriber[T any] interface {
+	C() chan struct{}
+	Value() T
+	Done() <-chan struct{}
+	Err() error
+	Dropped() uint64
+	Close()
+}
+
+//NewObserver returns a new generic observer.
+func NewObserver[T any]() Observer[T] {
+	return Wrap[T](observer.NewObserver())
+}
+
+//Wrap returns a generic observer for an observer.Observer.
+//Values that are not of type T are received as the zero value of T.
+func Wrap[T any](o observer.Observer) Observer[T] {
+	return &typedObserver[T]{o: o}
+}
+
+type typedObserver[T any] struct {
+	o observer.Observer
+}
+
+//Notify sends out the value to the subscribers
+func (t *typedObserver[T]) Notify(value T) {
+	t.o.Notify(value)
+}
+
+//Subscribe returns a new generic subscriber
+func (t *typedObserver[T]) Subscribe() Subscriber[T] {
+	return WrapSubscriber[T](t.o.Subscribe())
+}
+
+//SubscribeWithPolicy returns a new generic subscriber with bounded lag
+func (t *typedObserver[T]) SubscribeWithPolicy(p observer.Policy) Subscriber[T] {
+	return WrapSubscriber[T](t.o.SubscribeWithPolicy(p))
+}
+
+//SubscribeWithReplay returns a new generic subscriber that starts with up to n recent values.
+//The values must have been kept with Replay (see observer.Observer).
+func (t *typedObserver[T]) SubscribeWithReplay(n int) Subscriber[T] {
+	return WrapSubscriber[T](t.o.SubscribeWithReplay(n))
+}
+
+//Replay sets the number of recent values that are kept for replay
+func (t *typedObserver[T]) Replay(n int) {
+	t.o.Replay(n)
+}
+
+//Latest returns the most recent value
+func (t *typedObserver[T]) Latest() (T, bool) {
+	v, ok := t.o.Latest()
+	if !ok {
+		var zero T
+		return zero, false
+	}
+	value, ok := v.(T)
+	return value, ok
+}
+
+//Unsubscribe detaches the subscriber from the observer
+func (t *typedObserver[T]) Unsubscribe(s Subscriber[T]) {
+	if ts, ok := s.(*typedSubscriber[T]); ok {
+		t.o.Unsubscribe(ts.s)
+	}
+}
+
+//Subscribers returns the number of active subscribers
+func (t *typedObserver[T]) Subscribers() int {
+	return t.o.Subscribers()
+}
+
+//OnIdle registers a function that is called when the last subscriber leaves
+func (t *typedObserver[T]) OnIdle(fn func()) {
+	t.o.OnIdle(fn)
+}
+
+//Errors returns the channel with the forwarded errors
+func (t *typedObserver[T]) Errors() <-chan error {
+	return t.o.Errors()
+}
+
+//Close shuts down the underlying observer
+func (t *typedObserver[T]) Close() {
+	t.o.Close()
+}
+
+//Untyped returns the underlying observer
+func (t *typedObserver[T]) Untyped() observer.Observer {
+	return t.o
+}
+
+//WrapSubscriber returns a generic subscriber for an observer.Subscriber.
+func WrapSubscriber[T any](s observer.Subscriber) Subscriber[T] {
+	return &typedSubscriber[T]{s: s}
+}
+
+type typedSubscriber[T any] struct {
+	s observer.Subscriber
+}
+
+//C returns a channel and signals if Value() can be called
+func (t *typedSubscriber[T]) C() chan struct{} {
+	return t.s.C()
+}
+
+//Value returns the current value
+func (t *typedSubscriber[T]) Value() T {
+	v, _ := t.s.Value().(T)
+	return v
+}
+
+//Done returns a channel that is closed when the observer has finished
+func (t *typedSubscriber[T]) Done() <-chan struct{} {
+	return t.s.Done()
+}
+
+//Err returns the error that ended the stream
+func (t *typedSubscriber[T]) Err() error {
+	return t.s.Err()
+}
+
+//Dropped returns the number of dropped values
+func (t *typedSubscriber[T]) Dropped() uint64 {
+	return t.s.Dropped()
+}
+
+//Close detaches the subscriber from the observer
+func (t *typedSubscriber[T]) Close() {
+	t.s.Close()
+}
@@ -0,0 +1,138 @@
+package typed
+
+import (
+	"context"
+	"time"
+
+	"github.com/konimarti/flow"
+	"github.com/konimarti/flow/filters"
+	"github.com/konimarti/flow/observer"
+)
+
+//New returns an Observer that receives the results of the generic flow
+func New[In, Out any](f Filter[In, Out], s Source[In]) Observer[Out] {
+	return Wrap[Out](s.Run(Untyped(f)))
+}
+
+//NewWithContext returns an Observer that receives the results of the generic flow.
+//The flow is stopped and all subscribers are closed when the context is done.
+func NewWithContext[In, Out any](ctx context.Context, f Filter[In, Out], s Source[In]) Observer[Out] {
+	if cs, ok := s.(ContextSource[In]); ok {
+		return Wrap[Out](cs.RunContext(ctx, Untyped(f)))
+	}
+	return Wrap[Out](flow.NewWithContext(ctx, Untyped(f), &source[In]{s}))
+}
+
+//Source is the generic interface for input for the flow.
+//It feeds values of type T to the filter.
+type Source[T any] interface {
+	Run(f TypedFilter[T]) observer.Observer
+}
+
+//ContextSource is implemented by generic sources that can be stopped with a context
+type ContextSource[T any] interface {
+	Source[T]
+	RunContext(ctx context.Context, f TypedFilter[T]) observer.Observer
+}
+
+//source adapts a generic source to a flow.Source
+type source[T any] struct {
+	s Source[T]
+}
+
+func (s *source[T]) Run(nf filters.Filter) observer.Observer {
+	return s.s.Run(nf.(TypedFilter[T]))
+}
+
+//TypedFilter is a filters.Filter that is guaranteed to accept values of type T.
+//It is created by Untyped and ensures that sources and filters agree on
+//the type of the values at compile time.
+type TypedFilter[T any] interface {
+	filters.Filter
+	in() T
+}
+
+func (u *untyped[In, Out]) in() In {
+	var in In
+	return in
+}
+
+//Func implements the generic Source interface and regularly calls a function
+type Func[T any] struct {
+	Fn      func() T
+	Refresh time.Duration
+}
+
+//Run calls the given function in regular intervals
+func (f *Func[T]) Run(nf TypedFilter[T]) observer.Observer {
+	return f.RunContext(context.Background(), nf)
+}
+
+//RunContext calls the given function in regular intervals until the context is done
+func (f *Func[T]) RunContext(ctx context.Context, nf TypedFilter[T]) observer.Observer {
+	fn := f.Fn
+	return (&flow.Func{
+		Fn:      func() interface{} { return fn() },
+		Refresh: f.Refresh,
+	}).RunContext(ctx, nf)
+}
+
+//Chan implements the generic Source interface and provides the input for the flow.
+//The flow ends when the channel is closed.
+type Chan[T any] struct {
+	Ch chan T
+}
+
+//Run passes the channel data to the filters
+func (c *Chan[T]) Run(nf TypedFilter[T]) observer.Observer {
+	return c.RunContext(context.Background(), nf)
+}
+
+//RunContext passes the channel data to the filters until the context is done
+func (c *Chan[T]) RunContext(ctx context.Context, nf TypedFilter[T]) observer.Observer {
+	ch := make(chan interface{})
+	o := (&flow.Chan{Ch: ch}).RunContext(ctx, nf)
+	go func() {
+		for {
+			select {
+			case v, ok := <-c.Ch:
+				if !ok {
+					close(ch)
+					return
+				}
+				select {
+				case ch <- v:
+				case <-o.Control().E:
+					return
+				}
+			case <-o.Control().E:
+				return
+			}
+		}
+	}()
+	return o
+}
+
+//FromSource returns a generic source for a flow.Source.
+//The filter rejects values that are not of type T.
+func FromSource[T any](s flow.Source) Source[T] {
+	return &fromSource[T]{s: s}
+}
+
+type fromSource[T any] struct {
+	s flow.Source
+}
+
+func (f *fromSource[T]) Run(nf TypedFilter[T]) observer.Observer {
+	return f.s.Run(nf)
+}
+
+func (f *fromSource[T]) RunContext(ctx context.Context, nf TypedFilter[T]) observer.Observer {
+	return flow.NewWithContext(ctx, nf, f.s)
+}
+
+//FromObserver returns a generic source that passes the values of an observer
+//to the filters, so that typed flows can be chained (see flow.FromObserver).
+func FromObserver[T any](o Observer[T]) Source[T] {
+	return FromSource[T](flow.FromObserver(o.Untyped()))
+}
@@ -0,0 +1,234 @@
+package typed_test
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/konimarti/flow"
+	"github.com/konimarti/flow/filters"
+	"github.com/konimarti/flow/typed"
+)
+
+func TestUntypedRejectsWrongType(t *testing.T) {
+	f := typed.Untyped[float64, float64](&typed.Model[float64]{})
+	if f.Check(1) {
+		t.Error("int should be rejected by a float64 filter")
+	}
+	if !f.Check(1.0) {
+		t.Error("float64 should be accepted")
+	}
+	if f.Update(2.0) != 2.0 {
+		t.Error("update failed")
+	}
+}
+
+func TestTyped(t *testing.T) {
+	f := typed.Typed[float64, float64](&filters.AboveFloat64{Value: 1.0})
+	if f.Check(0.5) {
+		t.Error("should not fire below threshold")
+	}
+	if !f.Check(1.5) || f.Update(1.5) != 1.5 {
+		t.Error("should fire above threshold")
+	}
+}
+
+func TestChainAndThen(t *testing.T) {
+	double := &typed.FilterFunc[int, int]{UpdateFn: func(v int) int { return 2 * v }}
+	positive := &typed.FilterFunc[int, int]{
+		CheckFn:  func(v int) bool { return v > 0 },
+		UpdateFn: func(v int) int { return v },
+	}
+	label := &typed.FilterFunc[int, string]{UpdateFn: func(v int) string {
+		if v > 10 {
+			return "high"
+		}
+		return "low"
+	}}
+	f := typed.Then[int, int, string](typed.NewChain[int](positive, double), label)
+
+	var config = []struct {
+		Value int
+		Check bool
+		Want  string
+	}{
+		{-1, false, ""},
+		{2, true, "low"},
+		{6, true, "high"},
+	}
+	for _, cfg := range config {
+		if check := f.Check(cfg.Value); check != cfg.Check {
+			t.Errorf("value %d: got check %v, expected %v", cfg.Value, check, cfg.Check)
+		}
+		if cfg.Check && f.Update(cfg.Value) != cfg.Want {
+			t.Errorf("value %d: got %s, expected %s", cfg.Value, f.Update(cfg.Value), cfg.Want)
+		}
+	}
+}
+
+func TestChanFlow(t *testing.T) {
+	ch := make(chan float64)
+	o := typed.New[float64, float64](
+		typed.Typed[float64, float64](&filters.MovingAverage{Window: 2}),
+		&typed.Chan[float64]{Ch: ch},
+	)
+	defer o.Close()
+	sub := o.Subscribe()
+
+	go func() {
+		ch <- 1.0
+		ch <- 3.0
+	}()
+
+	want := []float64{1.0, 2.0}
+	for _, w := range want {
+		select {
+		case <-time.After(1 * time.Second):
+			t.Fatal("timed out")
+		case <-sub.C():
+			if v := sub.Value(); v != w {
+				t.Errorf("got %v, expected %v", v, w)
+			}
+		}
+	}
+}
+
+func TestFromSource(t *testing.T) {
+	ch := make(chan interface{})
+	o := typed.New[float64, float64](
+		&typed.Model[float64]{},
+		typed.FromSource[float64](&flow.Chan{Ch: ch}),
+	)
+	defer o.Close()
+	sub := o.Subscribe()
+
+	go func() {
+		ch <- 1
+		ch <- "two"
+		ch <- 3.0
+	}()
+
+	select {
+	case <-time.After(1 * time.Second):
+		t.Fatal("timed out")
+	case <-sub.C():
+		if v := sub.Value(); v != 3.0 {
+			t.Errorf("got %v, expected 3.0", v)
+		}
+	}
+}
+
+func TestFuncFlow(t *testing.T) {
+	var i int
+	o := typed.New[int, int](
+		&typed.FilterFunc[int, int]{
+			CheckFn:  func(v int) bool { return v%2 == 0 },
+			UpdateFn: func(v int) int { return v * v },
+		},
+		&typed.Func[int]{Fn: func() int { i++; return i }, Refresh: 10 * time.Millisecond},
+	)
+	defer o.Close()
+	sub := o.Subscribe()
+
+	select {
+	case <-time.After(1 * time.Second):
+		t.Fatal("timed out")
+	case <-sub.C():
+		if v := sub.Value(); v != 4 {
+			t.Errorf("got %v, expected 4", v)
+		}
+	}
+}
+
+func TestNewWithContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	o := typed.NewWithContext[float64, float64](ctx, &typed.Model[float64]{}, &typed.Chan[float64]{Ch: make(chan float64)})
+	sub := o.Subscribe()
+	cancel()
+
+	select {
+	case <-time.After(1 * time.Second):
+		t.Fatal("timed out")
+	case <-sub.Done():
+	}
+}
+
+func TestFromObserver(t *testing.T) {
+	ch := make(chan int)
+	upstream := typed.New[int, int](typed.NewChain[int](), &typed.Chan[int]{Ch: ch})
+	defer upstream.Close()
+
+	double := &typed.FilterFunc[int, string]{
+		UpdateFn: func(v int) string { return strconv.Itoa(2 * v) },
+	}
+	downstream := typed.New[int, string](double, typed.FromObserver(upstream))
+	defer downstream.Close()
+	subscriber := downstream.Subscribe()
+
+	ch <- 21
+	<-subscriber.C()
+	if v := subscriber.Value(); v != "42" {
+		t.Errorf("Got %q. Expected 42", v)
+	}
+}
+
+func TestObserverClose(t *testing.T) {
+	o := typed.NewObserver[int]()
+	sub := o.Subscribe()
+
+	done := make(chan bool, 1)
+	go func() {
+		o.Close()
+		done <- true
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Close blocked without a run loop")
+	}
+	select {
+	case <-sub.Done():
+	default:
+		t.Error("subscriber should be done")
+	}
+}
+
+func TestTypedWrongOutput(t *testing.T) {
+	f := typed.Typed[float64, string](&filters.None{})
+	if f.Check(1.0) {
+		t.Error("float64 result should be rejected by a string filter")
+	}
+	if err := f.(filters.ErrReporter).Err(); err == nil || err.Error() != "typed: *filters.None returned 1 of type float64, not string" {
+		t.Errorf("Got %v. Expected type mismatch", err)
+	}
+
+	ch := make(chan float64)
+	o := typed.New[float64, string](f, &typed.Chan[float64]{Ch: ch})
+	defer o.Close()
+	ch <- 2.0
+	select {
+	case err := <-o.Errors():
+		if err == nil {
+			t.Error("expected type mismatch")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("type mismatch should be forwarded")
+	}
+}
+
+func TestObserverUnsubscribe(t *testing.T) {
+	o := typed.NewObserver[int]()
+	idle := make(chan bool, 1)
+	o.OnIdle(func() { idle <- true })
+	sub := o.Subscribe()
+	o.Unsubscribe(sub)
+	select {
+	case <-idle:
+	default:
+		t.Error("idle hook should be called")
+	}
+	if n := o.Subscribers(); n != 0 {
+		t.Errorf("Got %d subscribers. Expected 0", n)
+	}
+}